@@ -1,14 +1,21 @@
 package main
 
 import (
+	"flag"
+
 	"github.com/shyamsundaar/karino-mock-server/models"
 	"gorm.io/gen"
 )
 
 func main() {
+	// make check-generate writes to a temporary directory and compares it
+	// with the committed ./query
+	outPath := flag.String("out", "./query", "output directory; its name is the package name")
+	flag.Parse()
+
 	// Initialize the generator
 	g := gen.NewGenerator(gen.Config{
-		OutPath: "./query", // Path relative to this file
+		OutPath: *outPath, // Path relative to the working directory
 		Mode:    gen.WithDefaultQuery | gen.WithQueryInterface | gen.WithoutContext,
 	})
 
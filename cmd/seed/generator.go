@@ -0,0 +1,448 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/services"
+	"gorm.io/gorm"
+)
+
+var maleNames = []string{
+	"Venkata", "Srinivas", "Ramesh", "Suresh", "Nagaraju", "Prasad", "Ravi", "Krishna", "Sai", "Anil",
+	"Rajesh", "Mahesh", "Chandra", "Narasimha", "Koteswara", "Subba", "Raghava", "Gopal", "Hanumantha", "Satyanarayana",
+}
+
+var femaleNames = []string{
+	"Lakshmi", "Padma", "Saraswathi", "Anitha", "Sujatha", "Radha", "Bhavani", "Kavitha", "Swathi", "Durga",
+	"Sravani", "Jyothi", "Renuka", "Vijaya", "Annapurna", "Manjula", "Sirisha", "Madhavi", "Rama", "Kalyani",
+}
+
+var surnames = []string{
+	"Reddy", "Naidu", "Rao", "Chowdary", "Kumar", "Yadav", "Goud", "Varma", "Raju", "Sharma",
+	"Patel", "Singh", "Babu", "Murthy", "Prasad", "Nayak", "Shetty", "Gowda", "Pillai", "Kamma",
+}
+
+// Relative frequency of the KYC documents farmers register with. Other
+// types of the catalogue get otherKycWeight.
+var kycWeights = map[string]int{
+	"AADHAAR":     60,
+	"PAN":         15,
+	"VOTER_ID":    15,
+	"RATION_CARD": 7,
+	"PASSPORT":    3,
+}
+
+const otherKycWeight = 3
+
+// maxKycAttempts bounds the search for an unused KYC ID, for patterns that
+// allow few of them
+const maxKycAttempts = 10000
+
+// options are the command line settings of a generation run
+type options struct {
+	count          int
+	coops          []string
+	clubsPerCoop   int
+	clubShare      float64
+	vendorShare    float64
+	bothShare      float64
+	approvedShare  float64
+	deletedShare   float64
+	from, to       time.Time
+	farmerIDPrefix string
+	customerPrefix string
+	vendorPrefix   string
+	firstID        uint
+}
+
+// generator builds farmers whose fields pass the checks of the create
+// endpoints
+type generator struct {
+	opts        options
+	rand        *rand.Rand
+	kycTypes    []models.KycType
+	kycPatterns map[int]kycPattern // by KYC type ID
+	kycStored   func(kycID string) bool
+	addresses   []services.Address
+	names       map[string]string // geography level/ID to node name, for club names
+
+	clubs   map[string]*models.Club // by coop/club ID
+	kycIDs  map[string]bool
+	mobiles map[string]bool
+}
+
+// kycPattern is the format of a KYC type, parsed to generate IDs and
+// compiled to check them
+type kycPattern struct {
+	parsed   *syntax.Regexp
+	compiled *regexp.Regexp
+}
+
+// newGenerator generates KYC IDs in the formats of kycTypes, skipping those
+// kycStored reports as taken; a nil kycStored takes none
+func newGenerator(opts options, seed int64, kycTypes []models.KycType, kycStored func(kycID string) bool, geography *services.Geography) (*generator, error) {
+	g := &generator{
+		opts:        opts,
+		rand:        rand.New(rand.NewSource(seed)),
+		kycTypes:    kycTypes,
+		kycPatterns: map[int]kycPattern{},
+		kycStored:   kycStored,
+		names:       map[string]string{},
+		clubs:       map[string]*models.Club{},
+		kycIDs:      map[string]bool{},
+		mobiles:     map[string]bool{},
+	}
+	if g.kycStored == nil {
+		g.kycStored = func(string) bool { return false }
+	}
+
+	if len(kycTypes) == 0 {
+		return nil, fmt.Errorf("the KYC catalogue is empty")
+	}
+	for _, kycType := range kycTypes {
+		pattern, err := newKycPattern(g.rand, kycType.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("KYC type %s: %w", kycType.Name, err)
+		}
+		g.kycPatterns[kycType.ID] = pattern
+	}
+
+	g.addresses = addresses(geography, g.names)
+	return g, nil
+}
+
+// newKycPattern parses the pattern and checks that IDs generated from it
+// match it
+func newKycPattern(r *rand.Rand, pattern string) (kycPattern, error) {
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return kycPattern{}, err
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return kycPattern{}, err
+	}
+	for range 100 {
+		if compiled.MatchString(patternString(r, parsed)) {
+			return kycPattern{parsed: parsed, compiled: compiled}, nil
+		}
+	}
+	return kycPattern{}, fmt.Errorf("cannot generate IDs matching %s", pattern)
+}
+
+// addresses lists every valid settlement address of the geography master
+// data, each paired with a custom geography. A nil geography gives none.
+func addresses(geography *services.Geography, names map[string]string) []services.Address {
+	customs := []services.Address{{}}
+	if custom2 := geography.List(models.GeoCustom2, "", ""); len(custom2) > 0 {
+		customs = nil
+		for _, node := range custom2 {
+			customs = append(customs, services.Address{CustomGeo1ID: node.ParentID, CustomGeo2ID: node.ID})
+		}
+	}
+
+	var list []services.Address
+	add := func(settlement models.GeographyNode, part *models.GeographyNode) {
+		regionPart, _ := geography.Find(models.GeoRegionPart, settlement.ParentID)
+		address := services.Address{
+			RegionID:     atoi(regionPart.ParentID),
+			RegionPartID: atoi(regionPart.ID),
+			SettlementID: atoi(settlement.ID),
+			ZipCode:      settlement.ZipCode,
+		}
+		if part != nil {
+			address.SettlementPartID = atoi(part.ID)
+			if part.ZipCode != "" {
+				address.ZipCode = part.ZipCode
+			}
+		}
+		custom := customs[len(list)%len(customs)]
+		address.CustomGeo1ID, address.CustomGeo2ID = custom.CustomGeo1ID, custom.CustomGeo2ID
+		names[strconv.Itoa(address.SettlementID)] = settlement.Name
+		list = append(list, address)
+	}
+
+	for _, settlement := range geography.List(models.GeoSettlement, "", "") {
+		add(settlement, nil)
+		for _, part := range geography.Children(models.GeoSettlement, settlement.ID) {
+			add(settlement, &part)
+		}
+	}
+	return list
+}
+
+// farmer generates the n-th farmer, n starting at 0
+func (g *generator) farmer(n int) models.FarmerDetails {
+	id := g.opts.firstID + uint(n)
+	coopID := g.opts.coops[n%len(g.opts.coops)]
+
+	first := maleNames
+	if g.rand.Intn(2) == 0 {
+		first = femaleNames
+	}
+	farmer := models.FarmerDetails{
+		ID:           id,
+		CoopID:       coopID,
+		FarmerID:     fmt.Sprintf("%s%06d", g.opts.farmerIDPrefix, id),
+		FirstName:    pick(g.rand, first),
+		LastName:     pick(g.rand, surnames),
+		MobileNumber: g.mobile(),
+	}
+
+	if len(g.addresses) > 0 {
+		address := g.addresses[g.rand.Intn(len(g.addresses))]
+		farmer.RegionID = address.RegionID
+		farmer.RegionPartID = address.RegionPartID
+		farmer.SettlementID = address.SettlementID
+		farmer.SettlementPartID = address.SettlementPartID
+		farmer.CustomGeographyStructure1ID = address.CustomGeo1ID
+		farmer.CustomGeographyStructure2ID = address.CustomGeo2ID
+		farmer.ZipCode = address.ZipCode
+	}
+
+	// Club members may register on their leader's KYC, see finishClubs
+	if g.opts.clubsPerCoop > 0 && g.rand.Float64() < g.opts.clubShare {
+		club := g.club(coopID, g.rand.Intn(g.opts.clubsPerCoop)+1, farmer)
+		farmer.ClubID, farmer.ClubName = club.ID, club.Name
+	}
+	if farmer.ClubID == "" || g.rand.Float64() >= 0.3 {
+		g.addKyc(&farmer)
+	}
+
+	// Registered in the field first, synced to the ERP within a week
+	span := g.opts.to.Sub(g.opts.from)
+	raithuCreated := g.before(g.opts.from.Add(time.Duration(g.rand.Int63n(int64(span) + 1))))
+	created := g.before(raithuCreated.Add(time.Duration(g.rand.Int63n(int64(7 * 24 * time.Hour)))))
+	raithuUpdated := raithuCreated
+	updated := created
+	if g.rand.Intn(3) == 0 {
+		raithuUpdated = g.before(raithuCreated.Add(time.Duration(g.rand.Int63n(int64(90 * 24 * time.Hour)))))
+		updated = g.before(maxTime(raithuUpdated, created).Add(time.Duration(g.rand.Int63n(int64(24 * time.Hour)))))
+	}
+	farmer.RaithuCreatedDate, farmer.RaithuUpdatedAt = &raithuCreated, &raithuUpdated
+	farmer.CreatedAt = &created
+
+	role := g.rand.Float64()
+	switch {
+	case role < g.opts.bothShare:
+		updated = maxTime(updated, g.register(&farmer, models.RoleCustomer, created))
+		updated = maxTime(updated, g.register(&farmer, models.RoleVendor, created))
+	case role < g.opts.bothShare+g.opts.vendorShare:
+		updated = maxTime(updated, g.register(&farmer, models.RoleVendor, created))
+	default:
+		updated = maxTime(updated, g.register(&farmer, models.RoleCustomer, created))
+	}
+	farmer.UpdatedAt = &updated
+
+	// Club members stay, so every club keeps a registered leader
+	if farmer.ClubID == "" && g.rand.Float64() < g.opts.deletedShare {
+		farmer.DeletedAt = gorm.DeletedAt{Time: g.before(updated.Add(time.Duration(g.rand.Int63n(int64(30 * 24 * time.Hour))))), Valid: true}
+	}
+	return farmer
+}
+
+// register adds a role the way AddRole does and, for most farmers, the
+// ERP approval the promotion engine would have given. It returns the time
+// of the last change.
+func (g *generator) register(farmer *models.FarmerDetails, role string, at time.Time) time.Time {
+	tempID := uuid.Must(uuid.NewRandomFromReader(g.rand)).String()
+	status := models.RegistrationPending
+	approved := g.rand.Float64() < g.opts.approvedShare
+	if approved {
+		status = models.RegistrationApproved
+	}
+	approvedAt := g.before(at.Add(time.Duration(g.rand.Int63n(int64(48 * time.Hour)))))
+
+	switch role {
+	case models.RoleCustomer:
+		farmer.TempID, farmer.CustomerStatus, farmer.CustomerRegisteredAt = tempID, status, &at
+		if approved {
+			farmer.CustomerID = fmt.Sprintf("%s%07d", g.opts.customerPrefix, farmer.ID)
+			farmer.CustIDUpdateAt = &approvedAt
+		}
+	case models.RoleVendor:
+		farmer.TempVendorID, farmer.VendorStatus, farmer.VendorRegisteredAt = tempID, status, &at
+		if approved {
+			farmer.VendorID = fmt.Sprintf("%s%07d", g.opts.vendorPrefix, farmer.ID)
+			farmer.VendorIDUpdateAt = &approvedAt
+		}
+	}
+	if approved {
+		return approvedAt
+	}
+	return at
+}
+
+// club returns the club, named after the settlement of its first member
+func (g *generator) club(coopID string, number int, farmer models.FarmerDetails) *models.Club {
+	clubID := fmt.Sprintf("CL%03d", number)
+	key := coopID + "/" + clubID
+	if club, ok := g.clubs[key]; ok {
+		return club
+	}
+
+	name := fmt.Sprintf("Rythu Club %d", number)
+	if settlement := g.names[strconv.Itoa(farmer.SettlementID)]; settlement != "" {
+		name = fmt.Sprintf("%s Rythu Club %d", settlement, number)
+	}
+	club := &models.Club{CoopID: coopID, ID: clubID, Name: name}
+	g.clubs[key] = club
+	return club
+}
+
+// finishClubs makes the earliest registered member of each club its leader,
+// so every other member references a farmer registered before them, and
+// returns the clubs ordered by coop and club ID
+func (g *generator) finishClubs(farmers []models.FarmerDetails) []models.Club {
+	leaders := map[string]*models.FarmerDetails{}
+	for i := range farmers {
+		farmer := &farmers[i]
+		if farmer.ClubID == "" {
+			continue
+		}
+		key := farmer.CoopID + "/" + farmer.ClubID
+		if leader := leaders[key]; leader == nil || farmer.CreatedAt.Before(*leader.CreatedAt) {
+			leaders[key] = farmer
+		}
+	}
+
+	for i := range farmers {
+		farmer := &farmers[i]
+		if farmer.ClubID == "" {
+			continue
+		}
+		key := farmer.CoopID + "/" + farmer.ClubID
+		leader, club := leaders[key], g.clubs[key]
+		if farmer == leader {
+			// Only members may rely on someone else's KYC
+			if farmer.FarmerKycID == "" {
+				g.addKyc(farmer)
+			}
+			club.LeaderFarmerID = farmer.FarmerID
+			club.CreatedAt = farmer.CreatedAt
+		} else {
+			farmer.ClubLeaderFarmerID = leader.FarmerID
+		}
+		if club.UpdatedAt == nil || farmer.CreatedAt.After(*club.UpdatedAt) {
+			club.UpdatedAt = farmer.CreatedAt
+		}
+	}
+
+	clubs := make([]models.Club, 0, len(g.clubs))
+	for _, club := range g.clubs {
+		clubs = append(clubs, *club)
+	}
+	sort.Slice(clubs, func(i, j int) bool {
+		if clubs[i].CoopID != clubs[j].CoopID {
+			return clubs[i].CoopID < clubs[j].CoopID
+		}
+		return clubs[i].ID < clubs[j].ID
+	})
+	return clubs
+}
+
+func (g *generator) addKyc(farmer *models.FarmerDetails) {
+	kycType := g.kycType()
+	farmer.FarmerKycTypeID = kycType.ID
+	farmer.FarmerKycType = kycType.Name
+	farmer.FarmerKycID = g.kycID(kycType, farmer.LastName)
+}
+
+func (g *generator) kycType() models.KycType {
+	total := 0
+	for _, kycType := range g.kycTypes {
+		total += kycWeight(kycType)
+	}
+	n := g.rand.Intn(total)
+	for _, kycType := range g.kycTypes {
+		if n -= kycWeight(kycType); n < 0 {
+			return kycType
+		}
+	}
+	return g.kycTypes[0]
+}
+
+func kycWeight(kycType models.KycType) int {
+	if weight, ok := kycWeights[kycType.Name]; ok {
+		return weight
+	}
+	return otherKycWeight
+}
+
+// kycID generates an ID matching the catalogue pattern of the type, used
+// neither earlier in the run nor by a stored farmer
+func (g *generator) kycID(kycType models.KycType, surname string) string {
+	pattern := g.kycPatterns[kycType.ID]
+	for range maxKycAttempts {
+		id := patternString(g.rand, pattern.parsed)
+		// The 4th letter of a PAN is P for individuals, the 5th the surname
+		// initial, when the catalogue's format allows it
+		if kycType.Name == "PAN" && len(id) >= 5 {
+			if personal := id[:3] + "P" + surname[:1] + id[5:]; pattern.compiled.MatchString(personal) {
+				id = personal
+			}
+		}
+		if !pattern.compiled.MatchString(id) || g.kycIDs[id] || g.kycStored(id) {
+			continue
+		}
+		g.kycIDs[id] = true
+		return id
+	}
+	log.Fatalf("No unused %s ID matching %s found after %d attempts", kycType.Name, kycType.Pattern, maxKycAttempts)
+	return ""
+}
+
+func (g *generator) mobile() string {
+	for {
+		mobile := g.digits("6789", 1) + g.digits("0123456789", 9)
+		if !g.mobiles[mobile] {
+			g.mobiles[mobile] = true
+			return mobile
+		}
+	}
+}
+
+const alphanumerics = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// digits returns n characters drawn from charset
+func (g *generator) digits(charset string, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = charset[g.rand.Intn(len(charset))]
+	}
+	return string(b)
+}
+
+// before caps t at the end of the generated period, to the millisecond the
+// database keeps
+func (g *generator) before(t time.Time) time.Time {
+	if t.After(g.opts.to) {
+		t = g.opts.to
+	}
+	return t.Truncate(time.Millisecond)
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.Intn(len(values))]
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
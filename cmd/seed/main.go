@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shyamsundaar/karino-mock-server/initializers"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/repository"
+	"github.com/shyamsundaar/karino-mock-server/services"
+	"gorm.io/gorm/logger"
+)
+
+// Generates synthetic farmers for pagination and date-range sync tests,
+// written to the database configured in app.env or to a snapshot fixture
+// that POST /__admin/restore loads:
+//
+//	go run ./cmd/seed -n 5000
+//	go run ./cmd/seed -n 5000 -coops COOP001,COOP002 -out fixtures/farmers.json
+func main() {
+	var opts options
+	var coops, from, to, out string
+	var seed int64
+	flag.IntVar(&opts.count, "n", 1000, "number of farmers to generate")
+	flag.StringVar(&coops, "coops", "", "comma-separated coop IDs the farmers are spread over (default: the active cooperatives of COOPERATIVES_SEED_FILE)")
+	flag.IntVar(&opts.clubsPerCoop, "clubs", 5, "clubs per cooperative")
+	flag.Float64Var(&opts.clubShare, "club-share", 0.4, "share of farmers in a club")
+	flag.Float64Var(&opts.vendorShare, "vendor-share", 0.25, "share of farmers registered as vendors only")
+	flag.Float64Var(&opts.bothShare, "both-share", 0.15, "share of farmers registered as customers and vendors")
+	flag.Float64Var(&opts.approvedShare, "approved-share", 0.8, "share of registrations already approved by the ERP")
+	flag.Float64Var(&opts.deletedShare, "deleted-share", 0.02, "share of farmers soft-deleted")
+	flag.StringVar(&from, "from", time.Now().UTC().AddDate(-2, 0, 0).Format(time.DateOnly), "earliest raithuCreatedDate (YYYY-MM-DD)")
+	flag.StringVar(&to, "to", time.Now().UTC().Format(time.DateOnly), "latest raithuCreatedDate (YYYY-MM-DD)")
+	flag.StringVar(&opts.farmerIDPrefix, "prefix", "SF", "farmerId prefix")
+	flag.Int64Var(&seed, "seed", 0, "random seed, for reproducible data (default: time based)")
+	flag.StringVar(&out, "out", "", "write a snapshot fixture to this file instead of the database")
+	flag.Parse()
+
+	config, err := initializers.LoadConfig(".")
+	if err != nil {
+		log.Fatalln("Failed to load environment variables! \n", err.Error())
+	}
+	opts.customerPrefix = config.ErpCustomerCodePrefix
+	opts.vendorPrefix = config.ErpVendorCodePrefix
+
+	if opts.from, err = time.Parse(time.DateOnly, from); err != nil {
+		log.Fatalln("Invalid -from:", err)
+	}
+	if opts.to, err = time.Parse(time.DateOnly, to); err != nil {
+		log.Fatalln("Invalid -to:", err)
+	}
+	opts.to = opts.to.Add(24*time.Hour - time.Second)
+	if opts.count <= 0 || !opts.to.After(opts.from) {
+		log.Fatalln("-n must be positive and -from before -to")
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	cooperatives := loadCooperatives(config.CooperativesSeedFile, coops)
+	for _, coop := range cooperatives {
+		opts.coops = append(opts.coops, coop.ID)
+	}
+
+	geography, err := services.LoadGeography(config.GeographyFile)
+	if errors.Is(err, os.ErrNotExist) || config.GeographyFile == "" {
+		log.Println("No geography master data, farmers are generated without an address")
+	} else if err != nil {
+		log.Fatalln("Failed to load the geography master data! \n", err.Error())
+	}
+	kycTypes := services.DefaultKycTypes
+	if config.KycTypesFile != "" {
+		catalogue, err := services.LoadKycCatalogue(config.KycTypesFile)
+		if err != nil {
+			log.Fatalln("Failed to load the KYC type catalogue! \n", err.Error())
+		}
+		kycTypes = catalogue.List()
+	}
+
+	// Fixtures replace the whole state, so their IDs start at 1; database
+	// rows continue after the highest ID stored
+	var repos *repository.Repositories
+	opts.firstID = 1
+	if out == "" {
+		if config.DBDriver == "memory" {
+			log.Fatalln("DB_DRIVER=memory keeps nothing once this command exits; set mysql or sqlite, or use -out")
+		}
+		repos = initializers.ConnectRepositories(&config)
+		// Logging every insert would drown the output
+		initializers.DB.Logger = logger.Default.LogMode(logger.Warn)
+		opts.firstID = nextFarmerID(repos.Farmers)
+	}
+
+	// KYC IDs must also differ from those of the farmers already stored
+	var kycStored func(kycID string) bool
+	if repos != nil {
+		kycStored = func(kycID string) bool {
+			_, err := repos.Farmers.FindByKYC(kycID)
+			if err != nil && !errors.Is(err, repository.ErrFarmerNotFound) {
+				log.Fatalln("Failed to check the stored KYC IDs:", err)
+			}
+			return err == nil
+		}
+	}
+
+	g, err := newGenerator(opts, seed, kycTypes, kycStored, geography)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	farmers := make([]models.FarmerDetails, opts.count)
+	for i := range farmers {
+		farmers[i] = g.farmer(i)
+	}
+	clubs := g.finishClubs(farmers)
+
+	if out != "" {
+		writeFixture(out, models.Snapshot{TakenAt: time.Now().UTC(), Cooperatives: cooperatives, Clubs: clubs, Farmers: farmers})
+		log.Printf("Wrote %d farmers, %d clubs and %d cooperatives to %s (seed %d)", len(farmers), len(clubs), len(cooperatives), out, seed)
+		return
+	}
+
+	for i := range cooperatives {
+		if _, err := repos.Cooperatives.Find(cooperatives[i].ID); errors.Is(err, repository.ErrCooperativeNotFound) {
+			if err := repos.Cooperatives.Save(&cooperatives[i]); err != nil {
+				log.Fatalln("Failed to save cooperative", cooperatives[i].ID, err)
+			}
+		}
+	}
+	for i := range clubs {
+		if _, err := repos.Clubs.Find(clubs[i].CoopID, clubs[i].ID); errors.Is(err, repository.ErrClubNotFound) {
+			if err := repos.Clubs.Save(&clubs[i]); err != nil {
+				log.Fatalln("Failed to save club", clubs[i].ID, err)
+			}
+		}
+	}
+	if err := repos.Farmers.Import(farmers); err != nil {
+		log.Fatalln("Failed to save the farmers, nothing was written:", err)
+	}
+	log.Printf("Saved %d farmers with IDs %d to %d (seed %d)", len(farmers), opts.firstID, farmers[len(farmers)-1].ID, seed)
+}
+
+// loadCooperatives returns the cooperatives named by -coops, or the active
+// ones of the seed file. Unknown coop IDs are created active.
+func loadCooperatives(seedFile string, coops string) []models.Cooperative {
+	known := map[string]models.Cooperative{}
+	var active []models.Cooperative
+	if data, err := os.ReadFile(seedFile); err == nil {
+		var entries []models.CooperativeSchema
+		if err := json.Unmarshal(data, &entries); err != nil {
+			log.Fatalf("Failed to parse the cooperatives seed file %s: %v", seedFile, err)
+		}
+		for _, entry := range entries {
+			coop := newCooperative(entry.ID, entry.Name, entry.Active == nil || *entry.Active)
+			known[coop.ID] = coop
+			if coop.Active {
+				active = append(active, coop)
+			}
+		}
+	}
+
+	if coops == "" {
+		if len(active) == 0 {
+			log.Fatalln("No active cooperative in", seedFile, "- list the coop IDs with -coops")
+		}
+		return active
+	}
+
+	var selected []models.Cooperative
+	for _, id := range strings.Split(coops, ",") {
+		id = strings.TrimSpace(id)
+		coop, ok := known[id]
+		if !ok {
+			coop = newCooperative(id, id, true)
+		}
+		selected = append(selected, coop)
+	}
+	return selected
+}
+
+func newCooperative(id string, name string, active bool) models.Cooperative {
+	now := time.Now().UTC()
+	return models.Cooperative{ID: id, Name: name, Active: active, CreatedAt: &now, UpdatedAt: &now}
+}
+
+// nextFarmerID returns the ID after the highest one stored, soft-deleted
+// farmers included
+func nextFarmerID(repo repository.FarmerRepository) uint {
+	_, total, err := repo.List(repository.FarmerFilter{WithDeleted: true, Limit: 1})
+	if err != nil {
+		log.Fatalln("Failed to count the stored farmers:", err)
+	}
+	if total == 0 {
+		return 1
+	}
+	last, _, err := repo.List(repository.FarmerFilter{WithDeleted: true, Limit: 1, Offset: int(total - 1)})
+	if err != nil || len(last) == 0 {
+		log.Fatalln("Failed to find the highest farmer ID:", err)
+	}
+	return last[0].ID + 1
+}
+
+func writeFixture(path string, snapshot models.Snapshot) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Fatalln(err)
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+)
+
+// maxRepeat bounds the open-ended repetitions of a pattern, such as + and *
+const maxRepeat = 8
+
+// patternString returns a random string for the parsed regular expression.
+// Anchors and word boundaries write nothing, so the result still has to be
+// checked against the compiled pattern.
+func patternString(r *rand.Rand, re *syntax.Regexp) string {
+	var b strings.Builder
+	writePattern(&b, r, re)
+	return b.String()
+}
+
+func writePattern(b *strings.Builder, r *rand.Rand, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(classRune(r, re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(alphanumerics[r.Intn(len(alphanumerics))])
+	case syntax.OpCapture:
+		writePattern(b, r, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writePattern(b, r, sub)
+		}
+	case syntax.OpAlternate:
+		writePattern(b, r, re.Sub[r.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		low, high := repeatBounds(re)
+		for range low + r.Intn(high-low+1) {
+			writePattern(b, r, re.Sub[0])
+		}
+	}
+}
+
+func repeatBounds(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, maxRepeat
+	case syntax.OpPlus:
+		return 1, maxRepeat
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	if re.Max < 0 {
+		return re.Min, re.Min + maxRepeat
+	}
+	return re.Min, re.Max
+}
+
+// classRune picks a rune of the character class, given as lo-hi pairs,
+// preferring printable ASCII so negated classes do not yield exotic runes
+func classRune(r *rand.Rand, ranges []rune) rune {
+	if len(ranges) == 0 {
+		return 0
+	}
+
+	var ascii []rune
+	for i := 0; i < len(ranges); i += 2 {
+		if lo, hi := max(ranges[i], '!'), min(ranges[i+1], '~'); lo <= hi {
+			ascii = append(ascii, lo, hi)
+		}
+	}
+	if len(ascii) > 0 {
+		ranges = ascii
+	}
+
+	size := 0
+	for i := 0; i < len(ranges); i += 2 {
+		size += int(ranges[i+1]-ranges[i]) + 1
+	}
+	n := r.Intn(size)
+	for i := 0; i < len(ranges); i += 2 {
+		if width := int(ranges[i+1]-ranges[i]) + 1; n >= width {
+			n -= width
+		} else {
+			return ranges[i] + rune(n)
+		}
+	}
+	return ranges[0]
+}
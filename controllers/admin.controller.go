@@ -0,0 +1,303 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/middleware"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/repository"
+)
+
+// AdminController serves the admin endpoints that reset, seed, export and
+// restore the state of the mock between test runs
+type AdminController struct {
+	repos       *repository.Repositories
+	farmers     *FarmerController
+	idempotency *middleware.Idempotency
+	scenarios   *middleware.Scenarios
+}
+
+func NewAdminController(repos *repository.Repositories, farmers *FarmerController, idempotency *middleware.Idempotency, scenarios *middleware.Scenarios) *AdminController {
+	return &AdminController{repos: repos, farmers: farmers, idempotency: idempotency, scenarios: scenarios}
+}
+
+// ResetHandler handles POST /__admin/reset
+// @Summary      Remove every farmer and club
+// @Description  Cooperatives are kept. Stored idempotent responses are forgotten and scenarios go back to their loaded state.
+// @Tags         Admin
+// @Success      204
+// @Failure      401  {object}  models.ErrorFarmerResponse  "UNAUTHORIZED"
+// @Failure      502  {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /__admin/reset [post]
+func (h *AdminController) ResetHandler(c *fiber.Ctx) error {
+	// Farmers and clubs go together, so a failure leaves both in place
+	err := h.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Farmers.Truncate(); err != nil {
+			return err
+		}
+		return tx.Clubs.Truncate()
+	})
+	if err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+	h.resetRequestState()
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// SeedHandler handles POST /__admin/seed
+// @Summary      Load seed cooperatives and farmers
+// @Description  A JSON SeedSchema, or a text/csv list of farmers whose header row names SeedFarmer fields. Cooperatives are saved first; every farmer then goes through the checks of a single create and gets one result, as in a bulk create with mode=item.
+// @Tags         Admin
+// @Accept       json
+// @Accept       text/csv
+// @Produce      json
+// @Param        seed  body      models.SeedSchema           true  "Seed data"
+// @Success      201   {object}  models.BulkFarmerResponse   "every farmer registered"
+// @Success      207   {object}  models.BulkFarmerResponse   "some farmers rejected"
+// @Failure      400   {object}  models.BulkFarmerResponse   "every farmer rejected"
+// @Failure      400   {object}  models.ErrorFarmerResponse  "INVALID_BODY"
+// @Failure      401   {object}  models.ErrorFarmerResponse  "UNAUTHORIZED"
+// @Failure      422   {object}  models.ErrorFarmerResponse  "VALIDATION_ERROR"
+// @Router       /__admin/seed [post]
+func (h *AdminController) SeedHandler(c *fiber.Ctx) error {
+	var payload models.SeedSchema
+	var err error
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), "text/csv") {
+		payload.Farmers, err = parseSeedFarmersCSV(c.Body())
+	} else {
+		err = json.Unmarshal(c.Body(), &payload)
+	}
+	if err != nil {
+		return SendErrorResponse(c, invalidBodyError(err), "")
+	}
+
+	for i := range payload.Cooperatives {
+		if fields := models.ValidateStruct(&payload.Cooperatives[i]); len(fields) > 0 {
+			return SendErrorResponse(c, validationError(fields), "")
+		}
+	}
+	now := time.Now().UTC()
+	for _, entry := range payload.Cooperatives {
+		coop := models.Cooperative{
+			ID:        entry.ID,
+			Name:      entry.Name,
+			Active:    entry.Active == nil || *entry.Active,
+			CreatedAt: &now,
+			UpdatedAt: &now,
+		}
+		if err := h.repos.Cooperatives.Save(&coop); err != nil {
+			return SendErrorResponse(c, err, "")
+		}
+	}
+
+	results := make([]models.BulkFarmerResult, len(payload.Farmers))
+	for i := range payload.Farmers {
+		results[i] = h.seedFarmer(&payload.Farmers[i])
+	}
+	status, response := bulkResponse(BulkModeItem, results)
+	return c.Status(status).JSON(response)
+}
+
+func (h *AdminController) seedFarmer(seed *models.SeedFarmer) models.BulkFarmerResult {
+	if fields := models.ValidateStruct(seed); len(fields) > 0 {
+		return bulkErrorResult(seed.FarmerID, validationError(fields))
+	}
+	role := seed.Role
+	if role == "" {
+		role = models.RoleCustomer
+	}
+
+	coop, err := h.repos.Cooperatives.Find(seed.CoopID)
+	if errors.Is(err, repository.ErrCooperativeNotFound) || (err == nil && !coop.Active) {
+		return bulkErrorResult(seed.FarmerID, invalidCoopError("The cooperative "+seed.CoopID+" does not exist or is not active."))
+	}
+	if err != nil {
+		return bulkErrorResult(seed.FarmerID, err)
+	}
+	return h.farmers.registerBulkItem(seed.CoopID, &seed.CreateDetailSchema, role)
+}
+
+// SnapshotHandler handles GET /__admin/snapshot
+// @Summary      Export the whole state of the mock
+// @Description  Cooperatives, clubs and every farmer, soft-deleted ones included. POST it to /__admin/restore to get back to this state.
+// @Tags         Admin
+// @Produce      json
+// @Success      200  {object}  models.Snapshot
+// @Failure      401  {object}  models.ErrorFarmerResponse  "UNAUTHORIZED"
+// @Failure      502  {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /__admin/snapshot [get]
+func (h *AdminController) SnapshotHandler(c *fiber.Ctx) error {
+	snapshot := models.Snapshot{TakenAt: time.Now().UTC()}
+
+	var err error
+	if snapshot.Cooperatives, err = h.repos.Cooperatives.List(); err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+	if snapshot.Clubs, err = h.repos.Clubs.List(); err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+	if snapshot.Farmers, _, err = h.repos.Farmers.List(repository.FarmerFilter{WithDeleted: true}); err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+
+	// Empty sections are exported as [] rather than null
+	if snapshot.Cooperatives == nil {
+		snapshot.Cooperatives = []models.Cooperative{}
+	}
+	if snapshot.Clubs == nil {
+		snapshot.Clubs = []models.Club{}
+	}
+	if snapshot.Farmers == nil {
+		snapshot.Farmers = []models.FarmerDetails{}
+	}
+	return c.Status(fiber.StatusOK).JSON(snapshot)
+}
+
+// RestoreHandler handles POST /__admin/restore
+// @Summary      Replace the whole state of the mock with a snapshot
+// @Description  Everything stored is replaced in one transaction, so a rejected snapshot leaves the mock unchanged. Stored idempotent responses are forgotten and scenarios go back to their loaded state.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        snapshot  body      models.Snapshot             true  "Snapshot from GET /__admin/snapshot"
+// @Success      200       {object}  models.RestoreResponse
+// @Failure      400       {object}  models.ErrorFarmerResponse  "INVALID_BODY"
+// @Failure      401       {object}  models.ErrorFarmerResponse  "UNAUTHORIZED"
+// @Failure      409       {object}  models.ErrorFarmerResponse  "DUPLICATE_FARMER or DUPLICATE_KYC inside the snapshot"
+// @Failure      502       {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /__admin/restore [post]
+func (h *AdminController) RestoreHandler(c *fiber.Ctx) error {
+	var snapshot models.Snapshot
+	if err := json.Unmarshal(c.Body(), &snapshot); err != nil {
+		return SendErrorResponse(c, invalidBodyError(err), "")
+	}
+
+	err := h.repos.Transaction(func(tx *repository.Repositories) error {
+		return restoreSnapshot(tx, &snapshot)
+	})
+	if err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+	h.resetRequestState()
+
+	return c.Status(fiber.StatusOK).JSON(models.RestoreResponse{
+		Success:      true,
+		Cooperatives: len(snapshot.Cooperatives),
+		Clubs:        len(snapshot.Clubs),
+		Farmers:      len(snapshot.Farmers),
+	})
+}
+
+// restoreSnapshot replaces everything stored in repos with the snapshot
+func restoreSnapshot(repos *repository.Repositories, snapshot *models.Snapshot) error {
+	if err := repos.Farmers.Truncate(); err != nil {
+		return err
+	}
+	if err := repos.Clubs.Truncate(); err != nil {
+		return err
+	}
+	if err := repos.Cooperatives.Truncate(); err != nil {
+		return err
+	}
+
+	for i := range snapshot.Cooperatives {
+		if err := repos.Cooperatives.Save(&snapshot.Cooperatives[i]); err != nil {
+			return err
+		}
+	}
+	for i := range snapshot.Clubs {
+		if err := repos.Clubs.Save(&snapshot.Clubs[i]); err != nil {
+			return err
+		}
+	}
+	err := repos.Farmers.Import(snapshot.Farmers)
+	switch {
+	case errors.Is(err, repository.ErrDuplicateFarmer):
+		return newAPIError(fiber.StatusConflict, models.ErrCodeDuplicateFarmer, "The snapshot holds the same farmer twice.")
+	case errors.Is(err, repository.ErrDuplicateKYC):
+		return newAPIError(fiber.StatusConflict, models.ErrCodeDuplicateKYC, "The snapshot holds the same KYC ID twice.")
+	}
+	return err
+}
+
+// resetRequestState forgets what earlier requests left behind outside the
+// repositories
+func (h *AdminController) resetRequestState() {
+	h.idempotency.Reset()
+	h.scenarios.Reset()
+}
+
+// parseSeedFarmersCSV reads farmers from CSV whose header row holds the JSON
+// names of SeedFarmer fields. Empty cells are left out.
+func parseSeedFarmersCSV(data []byte) ([]models.SeedFarmer, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("CSV header: %w", err)
+	}
+	intFields := seedIntFields()
+
+	var farmers []models.SeedFarmer
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return farmers, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		record := map[string]any{}
+		for i, value := range row {
+			if value == "" || i >= len(header) {
+				continue
+			}
+			name := strings.TrimSpace(header[i])
+			if !intFields[name] {
+				record[name] = value
+				continue
+			}
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("CSV line %d: %s must be a number", line, name)
+			}
+			record[name] = number
+		}
+
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		var farmer models.SeedFarmer
+		if err := json.Unmarshal(encoded, &farmer); err != nil {
+			return nil, fmt.Errorf("CSV line %d: %w", line, err)
+		}
+		farmers = append(farmers, farmer)
+	}
+}
+
+// seedIntFields returns the JSON names of the integer fields of a farmer
+// registration
+func seedIntFields() map[string]bool {
+	fields := map[string]bool{}
+	schema := reflect.TypeOf(models.CreateDetailSchema{})
+	for i := 0; i < schema.NumField(); i++ {
+		field := schema.Field(i)
+		if field.Type.Kind() == reflect.Int {
+			fields[strings.SplitN(field.Tag.Get("json"), ",", 2)[0]] = true
+		}
+	}
+	return fields
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/middleware"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/repository"
+)
+
+const adminToken = "secret"
+
+// newAdminTestApp adds the admin routes to the farmer routes of newTestApp
+func newAdminTestApp(t *testing.T, repos *repository.Repositories) *fiber.App {
+	app := newTestApp(t, repos)
+	admin := NewAdminController(repos, nil, middleware.NewIdempotency(time.Hour), middleware.NewScenarios())
+	app.Route("/__admin", func(router fiber.Router) {
+		router.Use(middleware.RequireAdminToken(adminToken))
+		router.Post("/reset", admin.ResetHandler)
+		router.Get("/snapshot", admin.SnapshotHandler)
+		router.Post("/restore", admin.RestoreHandler)
+	})
+	return app
+}
+
+// sendAdmin makes one admin request with the token and decodes the JSON
+// response into out, when not nil
+func sendAdmin(t *testing.T, app *fiber.App, method, path, body string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set(middleware.AdminTokenHeader, adminToken)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	for name, repos := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			app := newAdminTestApp(t, repos)
+			send(t, app, fiber.MethodPost, "/customers/COOP001/farmers", farmerBody, nil)
+			send(t, app, fiber.MethodPost, "/customers/COOP001/farmers", secondFarmerBody, nil)
+			send(t, app, fiber.MethodDelete, "/customers/COOP001/farmers/F2", "", nil)
+
+			var snapshot json.RawMessage
+			if status := sendAdmin(t, app, fiber.MethodGet, "/__admin/snapshot", "", &snapshot); status != fiber.StatusOK {
+				t.Fatalf("snapshot got %d", status)
+			}
+			if status := sendAdmin(t, app, fiber.MethodPost, "/__admin/reset", "", nil); status != fiber.StatusNoContent {
+				t.Fatalf("reset got %d", status)
+			}
+			if status := send(t, app, fiber.MethodGet, "/customers/COOP001/farmers/F1", "", nil); status != fiber.StatusNotFound {
+				t.Errorf("GET after reset got %d, want 404", status)
+			}
+
+			var restored models.RestoreResponse
+			if status := sendAdmin(t, app, fiber.MethodPost, "/__admin/restore", string(snapshot), &restored); status != fiber.StatusOK || restored.Farmers != 2 {
+				t.Fatalf("restore got %d with %d farmers, want 200 with 2", status, restored.Farmers)
+			}
+			if status := send(t, app, fiber.MethodGet, "/customers/COOP001/farmers/F1", "", nil); status != fiber.StatusOK {
+				t.Errorf("GET F1 after restore got %d, want 200", status)
+			}
+			// F2 comes back deleted, as it was exported
+			if status := send(t, app, fiber.MethodPost, "/customers/COOP001/farmers/F2/restore", "", nil); status != fiber.StatusOK {
+				t.Errorf("restoring deleted F2 got %d, want 200", status)
+			}
+		})
+	}
+}
+
+func TestSnapshotErrors(t *testing.T) {
+	repos := testRepositories(t)["memory"]
+	app := newAdminTestApp(t, repos)
+	send(t, app, fiber.MethodPost, "/customers/COOP001/farmers", farmerBody, nil)
+
+	if status, code := errorCode(t, app, fiber.MethodGet, "/__admin/snapshot", ""); status != fiber.StatusUnauthorized || code != models.ErrCodeUnauthorized {
+		t.Errorf("without a token got %d %s, want 401 %s", status, code, models.ErrCodeUnauthorized)
+	}
+
+	twice := `{"farmers":[{"coopId":"COOP001","farmerId":"F9","customerStatus":"PENDING"},{"coopId":"COOP001","farmerId":"F9","customerStatus":"PENDING"}]}`
+	var response models.ErrorFarmerResponse
+	if status := sendAdmin(t, app, fiber.MethodPost, "/__admin/restore", twice, &response); status != fiber.StatusConflict || response.Code != models.ErrCodeDuplicateFarmer {
+		t.Errorf("duplicate snapshot got %d %s, want 409 %s", status, response.Code, models.ErrCodeDuplicateFarmer)
+	}
+	// The rejected snapshot left the stored farmers alone
+	if status := send(t, app, fiber.MethodGet, "/customers/COOP001/farmers/F1", "", nil); status != fiber.StatusOK {
+		t.Errorf("GET F1 after the rejected restore got %d, want 200", status)
+	}
+}
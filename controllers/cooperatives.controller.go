@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/repository"
+)
+
+// CooperativeController serves the cooperative registry admin endpoints and
+// the check that guards every farmer route
+type CooperativeController struct {
+	repo repository.CooperativeRepository
+}
+
+func NewCooperativeController(repo repository.CooperativeRepository) *CooperativeController {
+	return &CooperativeController{repo: repo}
+}
+
+// RequireActiveCooperative rejects requests whose :coopId is not a known,
+// active cooperative, the way the ERP does
+func (h *CooperativeController) RequireActiveCooperative(c *fiber.Ctx) error {
+	coop, err := h.repo.Find(c.Params("coopId"))
+	if errors.Is(err, repository.ErrCooperativeNotFound) {
+		return SendErrorResponse(c, invalidCoopError("The indicated cooperative does not exist."), "")
+	}
+	if err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+	if !coop.Active {
+		return SendErrorResponse(c, invalidCoopError("The indicated cooperative is not active."), "")
+	}
+	return c.Next()
+}
+
+// CreateCooperativeHandler handles POST /__admin/cooperatives
+// @Summary      Add a cooperative
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        cooperative  body      models.CooperativeSchema    true  "Cooperative"
+// @Success      201          {object}  models.CooperativeResponse
+// @Failure      400          {object}  models.ErrorFarmerResponse  "INVALID_BODY"
+// @Failure      409          {object}  models.ErrorFarmerResponse  "DUPLICATE_COOP"
+// @Failure      422          {object}  models.ErrorFarmerResponse  "VALIDATION_ERROR"
+// @Router       /__admin/cooperatives [post]
+func (h *CooperativeController) CreateCooperativeHandler(c *fiber.Ctx) error {
+	var payload models.CooperativeSchema
+	if err := c.BodyParser(&payload); err != nil {
+		return SendErrorResponse(c, invalidBodyError(err), "")
+	}
+	if fields := models.ValidateStruct(&payload); len(fields) > 0 {
+		return SendErrorResponse(c, validationError(fields), "")
+	}
+
+	if _, err := h.repo.Find(payload.ID); err == nil {
+		return SendErrorResponse(c, newAPIError(fiber.StatusConflict, models.ErrCodeDuplicateCoop, "The cooperative "+payload.ID+" already exists."), "")
+	}
+
+	now := time.Now().UTC()
+	coop := models.Cooperative{
+		ID:        payload.ID,
+		Name:      payload.Name,
+		Active:    payload.Active == nil || *payload.Active,
+		CreatedAt: &now,
+		UpdatedAt: &now,
+	}
+	if err := h.repo.Save(&coop); err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+	return c.Status(fiber.StatusCreated).JSON(models.CooperativeResponse{Success: true, Data: coop})
+}
+
+// FindCooperativesHandler handles GET /__admin/cooperatives
+// @Summary      List cooperatives
+// @Tags         Admin
+// @Produce      json
+// @Success      200  {object}  models.ListCooperativesResponse
+// @Failure      502  {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /__admin/cooperatives [get]
+func (h *CooperativeController) FindCooperativesHandler(c *fiber.Ctx) error {
+	coops, err := h.repo.List()
+	if err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+	return c.Status(fiber.StatusOK).JSON(models.ListCooperativesResponse{Success: true, Data: coops})
+}
+
+// GetCooperativeHandler handles GET /__admin/cooperatives/:coopId
+// @Summary      Get a cooperative
+// @Tags         Admin
+// @Produce      json
+// @Param        coopId  path      string  true  "Cooperative ID"
+// @Success      200     {object}  models.CooperativeResponse
+// @Failure      404     {object}  models.ErrorFarmerResponse  "INVALID_COOP"
+// @Router       /__admin/cooperatives/{coopId} [get]
+func (h *CooperativeController) GetCooperativeHandler(c *fiber.Ctx) error {
+	coop, err := h.findCooperative(c.Params("coopId"))
+	if err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+	return c.Status(fiber.StatusOK).JSON(models.CooperativeResponse{Success: true, Data: *coop})
+}
+
+// UpdateCooperativeHandler handles PATCH /__admin/cooperatives/:coopId
+// @Summary      Rename, activate or deactivate a cooperative
+// @Description  Only name and active are read from the body. Farmers of an inactive cooperative are kept but their routes answer INVALID_COOP.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        coopId       path      string                      true  "Cooperative ID"
+// @Param        cooperative  body      models.CooperativeSchema    true  "Fields to update"
+// @Success      200          {object}  models.CooperativeResponse
+// @Failure      400          {object}  models.ErrorFarmerResponse  "INVALID_BODY"
+// @Failure      404          {object}  models.ErrorFarmerResponse  "INVALID_COOP"
+// @Failure      422          {object}  models.ErrorFarmerResponse  "VALIDATION_ERROR"
+// @Router       /__admin/cooperatives/{coopId} [patch]
+func (h *CooperativeController) UpdateCooperativeHandler(c *fiber.Ctx) error {
+	coop, err := h.findCooperative(c.Params("coopId"))
+	if err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+
+	var present map[string]json.RawMessage
+	var payload models.CooperativeSchema
+	if err := json.Unmarshal(c.Body(), &present); err != nil {
+		return SendErrorResponse(c, invalidBodyError(err), "")
+	}
+	if err := json.Unmarshal(c.Body(), &payload); err != nil {
+		return SendErrorResponse(c, invalidBodyError(err), "")
+	}
+
+	if _, ok := present["name"]; ok {
+		if payload.Name == "" {
+			return SendErrorResponse(c, validationError([]*models.ErrorResponse{{Field: "name", Tag: "required", Message: "name is required"}}), "")
+		}
+		coop.Name = payload.Name
+	}
+	if payload.Active != nil {
+		coop.Active = *payload.Active
+	}
+
+	now := time.Now().UTC()
+	coop.UpdatedAt = &now
+	if err := h.repo.Save(coop); err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+	return c.Status(fiber.StatusOK).JSON(models.CooperativeResponse{Success: true, Data: *coop})
+}
+
+// DeleteCooperativeHandler handles DELETE /__admin/cooperatives/:coopId
+// @Summary      Remove a cooperative
+// @Description  Farmers of the cooperative are kept but their routes answer INVALID_COOP
+// @Tags         Admin
+// @Produce      json
+// @Param        coopId  path      string  true  "Cooperative ID"
+// @Success      200     {object}  models.CooperativeResponse
+// @Failure      404     {object}  models.ErrorFarmerResponse  "INVALID_COOP"
+// @Router       /__admin/cooperatives/{coopId} [delete]
+func (h *CooperativeController) DeleteCooperativeHandler(c *fiber.Ctx) error {
+	coop, err := h.findCooperative(c.Params("coopId"))
+	if err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+	if err := h.repo.Delete(coop.ID); err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+	return c.Status(fiber.StatusOK).JSON(models.CooperativeResponse{Success: true, Data: *coop})
+}
+
+func (h *CooperativeController) findCooperative(id string) (*models.Cooperative, error) {
+	coop, err := h.repo.Find(id)
+	if errors.Is(err, repository.ErrCooperativeNotFound) {
+		return nil, invalidCoopError("The cooperative " + id + " does not exist.")
+	}
+	return coop, err
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// apiError is an error meant for the client, with the HTTP status and the
+// machine-readable code to answer with
+type apiError struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  []*models.ErrorResponse
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+func newAPIError(status int, code string, message string) *apiError {
+	return &apiError{Status: status, Code: code, Message: message}
+}
+
+// validationError reports the CreateDetailSchema rules a payload breaks, or
+// INVALID_BODY when the payload could not be validated at all
+func validationError(fields []*models.ErrorResponse) *apiError {
+	if fields[0].Tag == models.InvalidPayloadTag {
+		return newAPIError(fiber.StatusBadRequest, models.ErrCodeInvalidBody, fields[0].Message)
+	}
+	message := "Validation failed: " + fields[0].Message + "."
+	if len(fields) > 1 {
+		message = fmt.Sprintf("Validation failed: %s, and %d more.", fields[0].Message, len(fields)-1)
+	}
+	return &apiError{Status: fiber.StatusUnprocessableEntity, Code: models.ErrCodeValidation, Message: message, Fields: fields}
+}
+
+func invalidBodyError(err error) *apiError {
+	return newAPIError(fiber.StatusBadRequest, models.ErrCodeInvalidBody, err.Error())
+}
+
+// errNullBody rejects a body that decodes to nothing, such as a JSON null
+var errNullBody = errors.New("the request body must be a JSON object")
+
+func invalidCoopError(message string) *apiError {
+	return newAPIError(fiber.StatusNotFound, models.ErrCodeInvalidCoop, message)
+}
+
+func farmerNotFoundError(message string) *apiError {
+	return newAPIError(fiber.StatusNotFound, models.ErrCodeFarmerNotFound, message)
+}
+
+func duplicateFarmerError(farmerId string, coopId string) *apiError {
+	return newAPIError(fiber.StatusConflict, models.ErrCodeDuplicateFarmer, "The Farmer ID "+farmerId+" is already registered in the cooperative "+coopId+".")
+}
+
+func detailsMismatchError(farmerId string, fields []string) *apiError {
+	return newAPIError(fiber.StatusConflict, models.ErrCodeDetailsMismatch, "The Farmer ID "+farmerId+" is already registered with other "+strings.Join(fields, ", ")+". Update the farmer before adding the role.")
+}
+
+func duplicateKYCError(kycId string) *apiError {
+	return newAPIError(fiber.StatusConflict, models.ErrCodeDuplicateKYC, "Farmer with the given KYC ID "+kycId+" already exists.")
+}
+
+// errorBody maps any error to the error envelope. Errors that are not an
+// apiError come from the storage layer.
+func errorBody(err error, farmerId string) (int, models.ErrorFarmerResponse) {
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		apiErr = newAPIError(fiber.StatusBadGateway, models.ErrCodeStorage, err.Error())
+	}
+	return apiErr.Status, models.ErrorFarmerResponse{
+		Success:  false,
+		Code:     apiErr.Code,
+		Message:  apiErr.Message,
+		FarmerId: farmerId,
+		Errors:   apiErr.Fields,
+	}
+}
+
+// SendErrorResponse writes err in the error envelope shared by every handler
+func SendErrorResponse(c *fiber.Ctx, err error, farmerId string) error {
+	status, body := errorBody(err, farmerId)
+	return c.Status(status).JSON(body)
+}
+
+// ErrorHandler answers errors that reach Fiber itself, such as unknown
+// routes or panics, with the same envelope as the handlers
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) {
+		return SendErrorResponse(c, newAPIError(fiber.StatusInternalServerError, models.ErrCodeInternal, err.Error()), "")
+	}
+
+	code := models.ErrCodeInternal
+	switch {
+	case fiberErr.Code == fiber.StatusNotFound:
+		code = models.ErrCodeNotFound
+	case fiberErr.Code < fiber.StatusInternalServerError:
+		code = models.ErrCodeBadRequest
+	}
+	message := fiberErr.Message
+	if message == "" {
+		message = http.StatusText(fiberErr.Code)
+	}
+	return SendErrorResponse(c, newAPIError(fiberErr.Code, code, message), "")
+}
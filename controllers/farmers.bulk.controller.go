@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/repository"
+)
+
+// Bulk registration modes
+const (
+	BulkModeTransaction = "transaction" // valid items are saved together, or none on a DB failure
+	BulkModeItem        = "item"        // each valid item is saved on its own
+)
+
+const maxBulkFarmers = 1000
+
+// BulkCreateCustomerDetailsHandler handles POST /spic_to_erp/customers/:coopId/farmers/bulk
+// @Summary      Register many farmers as customers
+// @Description  Apply the single-create validation and duplicate checks to every item and return one result per item. A rejected item carries the same error code as a single create.
+// @Tags         Details
+// @Accept       json
+// @Produce      json
+// @Param        coopId   path      string                       true   "Cooperative ID"
+// @Param        mode     query     string                       false  "transaction or item" default(transaction)
+// @Param        details  body      []models.CreateDetailSchema  true   "Farmers to register"
+// @Success      201      {object}  models.BulkFarmerResponse    "every item registered"
+// @Success      207      {object}  models.BulkFarmerResponse    "some items rejected"
+// @Failure      400      {object}  models.BulkFarmerResponse    "every item rejected"
+// @Failure      400      {object}  models.ErrorFarmerResponse   "INVALID_BODY or INVALID_QUERY"
+// @Failure      404      {object}  models.ErrorFarmerResponse   "INVALID_COOP"
+// @Failure      422      {object}  models.ErrorFarmerResponse   "VALIDATION_ERROR, batch size out of range"
+// @Router       /spic_to_erp/customers/{coopId}/farmers/bulk [post]
+func (h *FarmerController) BulkCreateCustomerDetailsHandler(c *fiber.Ctx) error {
+	return h.bulkRegisterFarmers(c, models.RoleCustomer)
+}
+
+// BulkCreateVendorDetailsHandler handles POST /spic_to_erp/vendors/:coopId/farmers/bulk
+// @Summary      Register many farmers as vendors
+// @Description  Apply the single-create validation and duplicate checks to every item and return one result per item. A rejected item carries the same error code as a single create.
+// @Tags         Details
+// @Accept       json
+// @Produce      json
+// @Param        coopId   path      string                       true   "Cooperative ID"
+// @Param        mode     query     string                       false  "transaction or item" default(transaction)
+// @Param        details  body      []models.CreateDetailSchema  true   "Farmers to register"
+// @Success      201      {object}  models.BulkFarmerResponse    "every item registered"
+// @Success      207      {object}  models.BulkFarmerResponse    "some items rejected"
+// @Failure      400      {object}  models.BulkFarmerResponse    "every item rejected"
+// @Failure      400      {object}  models.ErrorFarmerResponse   "INVALID_BODY or INVALID_QUERY"
+// @Failure      404      {object}  models.ErrorFarmerResponse   "INVALID_COOP"
+// @Failure      422      {object}  models.ErrorFarmerResponse   "VALIDATION_ERROR, batch size out of range"
+// @Router       /spic_to_erp/vendors/{coopId}/farmers/bulk [post]
+func (h *FarmerController) BulkCreateVendorDetailsHandler(c *fiber.Ctx) error {
+	return h.bulkRegisterFarmers(c, models.RoleVendor)
+}
+
+func (h *FarmerController) bulkRegisterFarmers(c *fiber.Ctx, role string) error {
+	coopId := c.Params("coopId")
+	mode := c.Query("mode", BulkModeTransaction)
+	if mode != BulkModeTransaction && mode != BulkModeItem {
+		return SendErrorResponse(c, newAPIError(fiber.StatusBadRequest, models.ErrCodeInvalidQuery, "mode must be "+BulkModeTransaction+" or "+BulkModeItem), "")
+	}
+
+	var payloads []models.CreateDetailSchema
+	if err := c.BodyParser(&payloads); err != nil {
+		return SendErrorResponse(c, invalidBodyError(err), "")
+	}
+	if len(payloads) == 0 || len(payloads) > maxBulkFarmers {
+		return SendErrorResponse(c, newAPIError(fiber.StatusUnprocessableEntity, models.ErrCodeValidation, "Provide between 1 and "+strconv.Itoa(maxBulkFarmers)+" farmers."), "")
+	}
+
+	results := make([]models.BulkFarmerResult, len(payloads))
+	if mode == BulkModeItem {
+		h.registerEachFarmer(coopId, payloads, role, results)
+	} else {
+		h.registerFarmersInTransaction(coopId, payloads, role, results)
+	}
+
+	status, response := bulkResponse(mode, results)
+	return c.Status(status).JSON(response)
+}
+
+// bulkResponse counts the results and picks the status: 201 when every item
+// succeeded, 400 when none did, 207 otherwise
+func bulkResponse(mode string, results []models.BulkFarmerResult) (int, models.BulkFarmerResponse) {
+	response := models.BulkFarmerResponse{Mode: mode, Results: results}
+	for _, result := range results {
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	response.Success = response.Failed == 0
+
+	status := fiber.StatusMultiStatus
+	switch {
+	case response.Failed == 0:
+		status = fiber.StatusCreated
+	case response.Succeeded == 0:
+		status = fiber.StatusBadRequest
+	}
+	return status, response
+}
+
+// registerEachFarmer saves every valid item on its own, so a failing item
+// never affects the others
+func (h *FarmerController) registerEachFarmer(coopId string, payloads []models.CreateDetailSchema, role string, results []models.BulkFarmerResult) {
+	for i := range payloads {
+		results[i] = h.registerBulkItem(coopId, &payloads[i], role)
+	}
+}
+
+// registerBulkItem validates and saves a single item
+func (h *FarmerController) registerBulkItem(coopId string, payload *models.CreateDetailSchema, role string) models.BulkFarmerResult {
+	farmer, isNew, err := h.prepareRegistration(h.repos, coopId, payload, role, time.Now().UTC())
+	if err == nil {
+		err = h.saveWithClub(farmer, role, isNew)
+	}
+	if err != nil {
+		return bulkErrorResult(payload.FarmerID, err)
+	}
+	return bulkSuccessResult(farmer, role)
+}
+
+// registerFarmersInTransaction saves the valid items in a single
+// transaction. Invalid items are skipped, but a DB failure rolls back the
+// whole batch.
+func (h *FarmerController) registerFarmersInTransaction(coopId string, payloads []models.CreateDetailSchema, role string, results []models.BulkFarmerResult) {
+	farmers := make([]*models.FarmerDetails, len(payloads))
+	rejected := make([]bool, len(payloads))
+
+	// Saved items and their clubs are visible to the transaction, so
+	// duplicates and club leaders inside the batch are checked the same way
+	// as those already stored. Clubs roll back with their farmers.
+	err := h.repos.Transaction(func(tx *repository.Repositories) error {
+		now := time.Now().UTC()
+		for i := range payloads {
+			payload := &payloads[i]
+
+			farmer, isNew, err := h.prepareRegistration(tx, coopId, payload, role, now)
+			if err != nil {
+				rejected[i] = true
+				results[i] = bulkErrorResult(payload.FarmerID, err)
+				continue
+			}
+			if err := saveRegistration(tx.Farmers, farmer, role, isNew); err != nil {
+				return err
+			}
+			if err := syncClub(tx.Clubs, farmer); err != nil {
+				return err
+			}
+			farmers[i] = farmer
+		}
+		return nil
+	})
+
+	for i := range payloads {
+		switch {
+		case rejected[i]:
+			// result already holds the validation error
+		case err != nil:
+			results[i] = bulkErrorResult(payloads[i].FarmerID, fmt.Errorf("batch rolled back: %w", err))
+		default:
+			results[i] = bulkSuccessResult(farmers[i], role)
+		}
+	}
+}
+
+func bulkSuccessResult(farmer *models.FarmerDetails, role string) models.BulkFarmerResult {
+	data := farmerResponse(farmer, role, "Farmer detail created successfully")
+	return models.BulkFarmerResult{Success: true, Data: &data}
+}
+
+// bulkErrorResult holds the error envelope a single create would have
+// answered with
+func bulkErrorResult(farmerId string, err error) models.BulkFarmerResult {
+	_, body := errorBody(err, farmerId)
+	return models.BulkFarmerResult{Success: false, Error: &body}
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/repository"
+)
+
+const secondFarmerBody = `{"farmerId":"F2","firstName":"Sita","lastName":"Devi","farmer_kyc_type_id":2,"farmer_kyc_type":"PAN","farmer_kyc_id":"FGHIJ5678K"}`
+
+func TestBulkCreate(t *testing.T) {
+	for _, mode := range []string{BulkModeTransaction, BulkModeItem} {
+		t.Run(mode, func(t *testing.T) {
+			app := newTestApp(t, testRepositories(t)["memory"])
+			path := "/customers/COOP001/farmers/bulk?mode=" + mode
+
+			var created models.BulkFarmerResponse
+			if status := send(t, app, fiber.MethodPost, path, "["+farmerBody+","+secondFarmerBody+"]", &created); status != fiber.StatusCreated || created.Succeeded != 2 {
+				t.Fatalf("got %d with %d registered, want 201 with 2", status, created.Succeeded)
+			}
+
+			// F1 is stored already, and the batch sends F3 twice
+			third := `{"farmerId":"F3","firstName":"Anil","lastName":"Rao","farmer_kyc_type_id":2,"farmer_kyc_type":"PAN","farmer_kyc_id":"KLMNO9012P"}`
+			var mixed models.BulkFarmerResponse
+			status := send(t, app, fiber.MethodPost, path, "["+farmerBody+","+third+","+third+"]", &mixed)
+			if status != fiber.StatusMultiStatus || mixed.Succeeded != 1 || mixed.Failed != 2 {
+				t.Fatalf("got %d with %d registered, %d rejected, want 207 with 1 and 2", status, mixed.Succeeded, mixed.Failed)
+			}
+			codes := []string{mixed.Results[0].Error.Code, mixed.Results[2].Error.Code}
+			if codes[0] != models.ErrCodeDuplicateFarmer || codes[1] != models.ErrCodeDuplicateFarmer {
+				t.Errorf("got codes %v, want %s for both", codes, models.ErrCodeDuplicateFarmer)
+			}
+		})
+	}
+}
+
+func TestBulkCreateErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		path, body string
+		status     int
+		code       string
+	}{
+		{"unknown mode", "/customers/COOP001/farmers/bulk?mode=all", "[" + farmerBody + "]", fiber.StatusBadRequest, models.ErrCodeInvalidQuery},
+		{"not an array", "/customers/COOP001/farmers/bulk", farmerBody, fiber.StatusBadRequest, models.ErrCodeInvalidBody},
+		{"empty batch", "/customers/COOP001/farmers/bulk", "[]", fiber.StatusUnprocessableEntity, models.ErrCodeValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, testRepositories(t)["memory"])
+			if status, code := errorCode(t, app, fiber.MethodPost, tt.path, tt.body); status != tt.status || code != tt.code {
+				t.Errorf("got %d %s, want %d %s", status, code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+// A write made while a memory transaction runs must survive its rollback
+func TestMemoryRollbackKeepsOutsideWrites(t *testing.T) {
+	repos := testRepositories(t)["memory"]
+	started := make(chan struct{})
+	written := make(chan error)
+	failed := errors.New("batch failed")
+
+	err := repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Farmers.Create(&models.FarmerDetails{CoopID: "COOP001", FarmerID: "F1"}); err != nil {
+			return err
+		}
+		go func() {
+			close(started)
+			written <- repos.Farmers.Create(&models.FarmerDetails{CoopID: "COOP001", FarmerID: "F2"})
+		}()
+		<-started
+		time.Sleep(10 * time.Millisecond)
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("got %v, want %v", err, failed)
+	}
+	if err := <-written; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repos.Farmers.FindByCoopAndFarmer("COOP001", "F1"); !errors.Is(err, repository.ErrFarmerNotFound) {
+		t.Errorf("rolled back farmer: got %v, want %v", err, repository.ErrFarmerNotFound)
+	}
+	if _, err := repos.Farmers.FindByCoopAndFarmer("COOP001", "F2"); err != nil {
+		t.Errorf("outside farmer: %v", err)
+	}
+}
@@ -1,245 +1,415 @@
 package controllers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/shyamsundaar/karino-mock-server/initializers"
 	"github.com/shyamsundaar/karino-mock-server/models"
-	// "github.com/shyamsundaar/karino-mock-server/query"
-	// "gorm.io/gorm"
+	"github.com/shyamsundaar/karino-mock-server/repository"
+	"github.com/shyamsundaar/karino-mock-server/services"
 )
 
+// FarmerController serves the customer and vendor farmer endpoints
+type FarmerController struct {
+	repos     *repository.Repositories
+	repo      repository.FarmerRepository
+	clubs     repository.ClubRepository
+	geography *services.Geography
+	kycTypes  *services.KycCatalogue
+}
+
+func NewFarmerController(repos *repository.Repositories, geography *services.Geography, kycTypes *services.KycCatalogue) *FarmerController {
+	return &FarmerController{repos: repos, repo: repos.Farmers, clubs: repos.Clubs, geography: geography, kycTypes: kycTypes}
+}
+
 // CreateCustomerDetailHandler handles POST /spic_to_erp/customers/:coopId/farmers
-// @Summary      Create a new farmer detail
-// @Description  Create a new record in the details table
+// @Summary      Register a farmer as a customer
+// @Description  Create the farmer, or add the customer role to a farmer already registered as a vendor. Adding the role writes no details, so the payload must match the stored farmer: any field that differs is answered with DETAILS_MISMATCH and has to be changed with PATCH first.
 // @Tags         Details
 // @Accept       json
 // @Produce      json
 // @Param        coopId  path      string                            true  "Cooperative ID"
+// @Param        Idempotency-Key  header  string                    false  "Replay the first response for retries with the same key and body"
 // @Param        detail  body      models.CreateDetailSchema          true  "Create Detail Payload"
 // @Success      201     {object}  models.CreateSuccessFarmerResponse
+// @Failure      400     {object}  models.ErrorFarmerResponse  "INVALID_BODY"
+// @Failure      404     {object}  models.ErrorFarmerResponse  "INVALID_COOP"
+// @Failure      409     {object}  models.ErrorFarmerResponse  "DUPLICATE_FARMER, DUPLICATE_KYC, DETAILS_MISMATCH or IDEMPOTENCY_KEY_REUSED"
+// @Failure      422     {object}  models.ErrorFarmerResponse  "VALIDATION_ERROR, INVALID_KYC, INVALID_GEOGRAPHY or INVALID_CLUB_LEADER"
+// @Failure      502     {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
 // @Router       /spic_to_erp/customers/{coopId}/farmers [post]
-func CreateCustomerDetailHandler(c *fiber.Ctx) error {
-	// 1. Get CoopID from URL Parameter
-	coopId := c.Params("coopId")
-	var payload *models.CreateDetailSchema
-	var existingFarmer models.FarmerDetails
-
-	// 2. Parse the JSON Body
-	if err := c.BodyParser(&payload); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"status": "fail", "message": err.Error()})
-	}
-
-	//3. Constraints for the payload check
-	if payload.FarmerID == "" {
-		return SendCustomerErrorResponse(c, "You must provide a Farmer ID.", payload.FarmerID)
-	}
+func (h *FarmerController) CreateCustomerDetailHandler(c *fiber.Ctx) error {
+	return h.registerFarmer(c, models.RoleCustomer)
+}
 
-	if payload.FirstName == "" || payload.LastName == "" {
-		return SendCustomerErrorResponse(c, "You must provide the first and last name.", payload.FarmerID)
+// validateDetail runs the validate tags of the schema. keep, when not nil,
+// limits the result to the fields it accepts.
+func validateDetail(payload *models.CreateDetailSchema, keep func(*models.ErrorResponse) bool) error {
+	var fields []*models.ErrorResponse
+	for _, field := range models.ValidateStruct(payload) {
+		if keep == nil || keep(field) {
+			fields = append(fields, field)
+		}
 	}
-
-	if payload.FarmerKycID == "" && payload.ClubLeaderFarmerID == "" {
-		return SendCustomerErrorResponse(c, "Either farmer_kyc_id or clubLeaderFarmerId must be provided.", payload.FarmerID)
+	if len(fields) == 0 {
+		return nil
 	}
+	return validationError(fields)
+}
 
-	kycId := initializers.DB.Where("farmer_kyc_id = ?", payload.FarmerKycID).First(&existingFarmer).Error
-	if kycId == nil {
-		return SendCustomerErrorResponse(c, "Farmer with the given KYC ID "+payload.FarmerKycID+" already exists.", payload.FarmerID)
+// parseUpdatedRange reads the updatedFrom/updatedTo query parameters. The
+// window is half-open: updatedFrom is inclusive and updatedTo is exclusive, so
+// consecutive sync windows never return the same farmer twice.
+func parseUpdatedRange(c *fiber.Ctx) (from *time.Time, to *time.Time, err error) {
+	if from, err = parseISODate(c.Query("updatedFrom")); err != nil {
+		return nil, nil, fmt.Errorf("invalid updatedFrom: %w", err)
 	}
-
-	if coopId == "" {
-		return SendCustomerErrorResponse(c, "The indicated cooperative does not exist.", payload.FarmerID)
+	if to, err = parseISODate(c.Query("updatedTo")); err != nil {
+		return nil, nil, fmt.Errorf("invalid updatedTo: %w", err)
 	}
-
-	farmerId := initializers.DB.Where("farmer_id = ? AND coop_id = ?", payload.FarmerID, coopId).First(&existingFarmer).Error
-	if farmerId == nil {
-		return SendCustomerErrorResponse(c, "The Farmer ID "+payload.FarmerID+" is already registered in the cooperative "+coopId+".", payload.FarmerID)
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, errors.New("updatedFrom must be before updatedTo")
 	}
+	return from, to, nil
+}
 
-	// 4. Map everything to the DB Model
-	newDetail := models.FarmerDetails{
-		CoopID:                      coopId, // Set from URL Param
-		FarmerID:                    payload.FarmerID,
-		FirstName:                   payload.FirstName,
-		LastName:                    payload.LastName,
-		MobileNumber:                payload.MobileNumber,
-		RegionID:                    payload.RegionID,
-		RegionPartID:                payload.RegionPartID,
-		SettlementID:                payload.SettlementID,
-		SettlementPartID:            payload.SettlementPartID,
-		CustomGeographyStructure1ID: payload.CustomGeo1ID,
-		CustomGeographyStructure2ID: payload.CustomGeo2ID,
-		ZipCode:                     payload.ZipCode,
-		FarmerKycTypeID:             payload.FarmerKycTypeID,
-		FarmerKycType:               payload.FarmerKycType,
-		FarmerKycID:                 payload.FarmerKycID,
-		ClubID:                      payload.ClubID,
-		ClubName:                    payload.ClubName,
-		ClubLeaderFarmerID:          payload.ClubLeaderFarmerID,
+// parseISODate accepts an RFC 3339 timestamp (e.g. 2025-12-30T05:03:17.863Z)
+// or a plain date (2025-12-30, midnight UTC). An empty value yields nil.
+func parseISODate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
 	}
 
-	// 5. Save to Database (GORM fills in CreatedAt/UpdatedAt here)
-	result := initializers.DB.Create(&newDetail)
-	if result.Error != nil {
-		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"status": "error", "message": result.Error.Error()})
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
 	}
+	return nil, fmt.Errorf("%q is not an ISO-8601 date, expected e.g. 2025-12-30T05:03:17Z or 2025-12-30", value)
+}
 
-	// Inside CreateCustomerDetailHandler...
+// FindCustomerDetailsHandler handles GET /spic_to_erp/customers/:coopId/farmers
+// @Summary      List customer farmer details
+// @Description  Get a paginated list of farmers registered as customers in a specific cooperative
+// @Tags         Details
+// @Accept       json
+// @Produce      json
+// @Param        coopId path      string  true   " "
+// @Param        updatedFrom   query     string  false  "Only farmers updated at or after this ISO-8601 time (inclusive)"
+// @Param        updatedTo     query     string  false  "Only farmers updated before this ISO-8601 time (exclusive)"
+// @Param        page          query     int     false  "Page number"    default(1)
+// @Param        limit         query     int     false  "Items per page" default(10)
+// @Param        includeDeleted query    bool    false  "Also return soft-deleted farmers" default(false)
+// @Success      200    {object}  models.ListFarmersResponse
+// @Failure      400    {object}  models.ErrorFarmerResponse  "INVALID_QUERY"
+// @Failure      404    {object}  models.ErrorFarmerResponse  "INVALID_COOP"
+// @Failure      502    {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /spic_to_erp/customers/{coopId}/farmers [get]
+func (h *FarmerController) FindCustomerDetailsHandler(c *fiber.Ctx) error {
+	return h.listFarmers(c, models.RoleCustomer)
+}
 
-	response := models.CreateSuccessFarmerResponse{
-		Success: true,
-		Data: models.FarmerResponse{
-			TempERPCustomerID: newDetail.TempID,
-			ErpCustomerId:     newDetail.CustomerID,
-			ErpVendorId:       newDetail.VendorID,
-			FarmerId:          newDetail.FarmerID,
-			CreatedAt:         newDetail.CreatedAt.Format("2006-01-02T15:04:05Z"),
-			UpdatedAt:         newDetail.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-			Message:           "Farmer detail created successfully",
-		},
-	}
-
-	return c.Status(fiber.StatusCreated).JSON(response)
-
-}
-
-func SendCustomerErrorResponse(c *fiber.Ctx, msg string, farmerId string) error {
-	now := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
-	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-		"success": false,
-		"data": fiber.Map{
-			"tempERPCustomerId": "0",
-			"erpCustomerId":     "",
-			"farmerId":          farmerId,
-			"createdAt":         now,
-			"updatedAt":         now,
-			"Message":           msg,
-		},
-	})
+// CreateVendorDetailHandler handles POST /spic_to_erp/vendors/:coopId/farmers
+// @Summary      Register a farmer as a vendor
+// @Description  Create the farmer, or add the vendor role to a farmer already registered as a customer. Adding the role writes no details, so the payload must match the stored farmer: any field that differs is answered with DETAILS_MISMATCH and has to be changed with PATCH first.
+// @Tags         Details
+// @Accept       json
+// @Produce      json
+// @Param        coopId  path      string                            true  "Cooperative ID"
+// @Param        Idempotency-Key  header  string                    false  "Replay the first response for retries with the same key and body"
+// @Param        detail  body      models.CreateDetailSchema          true  "Create Detail Payload"
+// @Success      201     {object}  models.CreateSuccessFarmerResponse
+// @Failure      400     {object}  models.ErrorFarmerResponse  "INVALID_BODY"
+// @Failure      404     {object}  models.ErrorFarmerResponse  "INVALID_COOP"
+// @Failure      409     {object}  models.ErrorFarmerResponse  "DUPLICATE_FARMER, DUPLICATE_KYC, DETAILS_MISMATCH or IDEMPOTENCY_KEY_REUSED"
+// @Failure      422     {object}  models.ErrorFarmerResponse  "VALIDATION_ERROR, INVALID_KYC, INVALID_GEOGRAPHY or INVALID_CLUB_LEADER"
+// @Failure      502     {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /spic_to_erp/vendors/{coopId}/farmers [post]
+func (h *FarmerController) CreateVendorDetailHandler(c *fiber.Ctx) error {
+	return h.registerFarmer(c, models.RoleVendor)
 }
 
-// FindDetails handles GET /spic_to_erp/customers/:coopId/farmers
-// @Summary      List farmer details
-// @Description  Get a paginated list of farmer details for a specific cooperative
+// FindVendorDetailsHandler handles GET /spic_to_erp/vendors/:coopId/farmers
+// @Summary      List vendor farmer details
+// @Description  Get a paginated list of farmers registered as vendors in a specific cooperative
 // @Tags         Details
 // @Accept       json
 // @Produce      json
 // @Param        coopId path      string  true   " "
-// @Param        updatedFrom   query     string  false  " "
-// @Param        updatedTo     query     string  false  " "
+// @Param        updatedFrom   query     string  false  "Only farmers updated at or after this ISO-8601 time (inclusive)"
+// @Param        updatedTo     query     string  false  "Only farmers updated before this ISO-8601 time (exclusive)"
 // @Param        page          query     int     false  "Page number"    default(1)
 // @Param        limit         query     int     false  "Items per page" default(10)
+// @Param        includeDeleted query    bool    false  "Also return soft-deleted farmers" default(false)
 // @Success      200    {object}  models.ListFarmersResponse
-// @Router       /spic_to_erp/customers/{coopId}/farmers [get]
-func FindCustomerDetailsHandler(c *fiber.Ctx) error {
-	coopId := c.Params("coopId")
+// @Failure      400    {object}  models.ErrorFarmerResponse  "INVALID_QUERY"
+// @Failure      404    {object}  models.ErrorFarmerResponse  "INVALID_COOP"
+// @Failure      502    {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /spic_to_erp/vendors/{coopId}/farmers [get]
+func (h *FarmerController) FindVendorDetailsHandler(c *fiber.Ctx) error {
+	return h.listFarmers(c, models.RoleVendor)
+}
 
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-	offset := (page - 1) * limit
+// FindCustomerClubFarmersHandler handles GET /spic_to_erp/customers/:coopId/clubs/:clubId/farmers
+// @Summary      List the customer members of a club
+// @Description  Get a paginated list of the farmers of a club registered as customers
+// @Tags         Details
+// @Produce      json
+// @Param        coopId  path      string  true   "Cooperative ID"
+// @Param        clubId  path      string  true   "Club ID"
+// @Param        page    query     int     false  "Page number"    default(1)
+// @Param        limit   query     int     false  "Items per page" default(10)
+// @Success      200     {object}  models.ClubFarmersResponse
+// @Failure      404     {object}  models.ErrorFarmerResponse  "CLUB_NOT_FOUND or INVALID_COOP"
+// @Router       /spic_to_erp/customers/{coopId}/clubs/{clubId}/farmers [get]
+func (h *FarmerController) FindCustomerClubFarmersHandler(c *fiber.Ctx) error {
+	return h.listClubFarmers(c, models.RoleCustomer)
+}
 
-	var farmers []models.FarmerDetails
-	var totalRecords int64
+// FindVendorClubFarmersHandler handles GET /spic_to_erp/vendors/:coopId/clubs/:clubId/farmers
+// @Summary      List the vendor members of a club
+// @Description  Get a paginated list of the farmers of a club registered as vendors
+// @Tags         Details
+// @Produce      json
+// @Param        coopId  path      string  true   "Cooperative ID"
+// @Param        clubId  path      string  true   "Club ID"
+// @Param        page    query     int     false  "Page number"    default(1)
+// @Param        limit   query     int     false  "Items per page" default(10)
+// @Success      200     {object}  models.ClubFarmersResponse
+// @Failure      404     {object}  models.ErrorFarmerResponse  "CLUB_NOT_FOUND or INVALID_COOP"
+// @Router       /spic_to_erp/vendors/{coopId}/clubs/{clubId}/farmers [get]
+func (h *FarmerController) FindVendorClubFarmersHandler(c *fiber.Ctx) error {
+	return h.listClubFarmers(c, models.RoleVendor)
+}
 
-	query := initializers.DB.
-		Model(&models.FarmerDetails{}).
-		Where("coop_id = ?", coopId)
+// GetCustomerDetailHandler handles GET /spic_to_erp/customers/:coopId/farmers/:farmerId
+// @Summary      Get a customer farmer detail
+// @Description  Get a single farmer registered as a customer in a specific cooperative
+// @Tags         Details
+// @Accept       json
+// @Produce      json
+// @Param        coopId path      string  true   " "
+// @Param        farmerId path      string  true   " "
+// @Success      200    {object}  models.FarmerDetailResponse
+// @Failure      404    {object}  models.ErrorFarmerResponse  "FARMER_NOT_FOUND or INVALID_COOP"
+// @Router       /spic_to_erp/customers/{coopId}/farmers/{farmerId} [get]
+func (h *FarmerController) GetCustomerDetailHandler(c *fiber.Ctx) error {
+	return h.getFarmer(c, models.RoleCustomer)
+}
 
-	query.Count(&totalRecords)
+// GetVendorDetailHandler handles GET /spic_to_erp/vendors/:coopId/farmers/:farmerId
+// @Summary      Get a vendor farmer detail
+// @Description  Get a single farmer registered as a vendor in a specific cooperative
+// @Tags         Details
+// @Accept       json
+// @Produce      json
+// @Param        coopId path      string  true   " "
+// @Param        farmerId path      string  true   " "
+// @Success      200    {object}  models.FarmerDetailResponse
+// @Failure      404    {object}  models.ErrorFarmerResponse  "FARMER_NOT_FOUND or INVALID_COOP"
+// @Router       /spic_to_erp/vendors/{coopId}/farmers/{farmerId} [get]
+func (h *FarmerController) GetVendorDetailHandler(c *fiber.Ctx) error {
+	return h.getFarmer(c, models.RoleVendor)
+}
 
-	if err := query.
-		Limit(limit).
-		Offset(offset).
-		Find(&farmers).Error; err != nil {
+// UpdateCustomerDetailHandler handles PATCH /spic_to_erp/customers/:coopId/farmers/:farmerId
+// @Summary      Update a customer farmer detail
+// @Description  Partially update a farmer registered as a customer. Only the fields present in the body are changed.
+// @Tags         Details
+// @Accept       json
+// @Produce      json
+// @Param        coopId    path      string                      true  "Cooperative ID"
+// @Param        farmerId  path      string                      true  "Farmer ID"
+// @Param        detail    body      models.CreateDetailSchema   true  "Fields to update"
+// @Success      200       {object}  models.CreateSuccessFarmerResponse
+// @Failure      400       {object}  models.ErrorFarmerResponse  "INVALID_BODY"
+// @Failure      404       {object}  models.ErrorFarmerResponse  "FARMER_NOT_FOUND or INVALID_COOP"
+// @Failure      409       {object}  models.ErrorFarmerResponse  "DUPLICATE_KYC"
+// @Failure      422       {object}  models.ErrorFarmerResponse  "VALIDATION_ERROR, FARMER_ID_IMMUTABLE, INVALID_KYC, INVALID_GEOGRAPHY or INVALID_CLUB_LEADER"
+// @Failure      502       {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /spic_to_erp/customers/{coopId}/farmers/{farmerId} [patch]
+func (h *FarmerController) UpdateCustomerDetailHandler(c *fiber.Ctx) error {
+	return h.updateFarmer(c, models.RoleCustomer)
+}
 
-		return c.Status(fiber.StatusBadGateway).JSON(models.ErrorFarmerResponse{
-			Success: false,
-			Message: err.Error(),
-		})
-	}
+// UpdateVendorDetailHandler handles PATCH /spic_to_erp/vendors/:coopId/farmers/:farmerId
+// @Summary      Update a vendor farmer detail
+// @Description  Partially update a farmer registered as a vendor. Only the fields present in the body are changed.
+// @Tags         Details
+// @Accept       json
+// @Produce      json
+// @Param        coopId    path      string                      true  "Cooperative ID"
+// @Param        farmerId  path      string                      true  "Farmer ID"
+// @Param        detail    body      models.CreateDetailSchema   true  "Fields to update"
+// @Success      200       {object}  models.CreateSuccessFarmerResponse
+// @Failure      400       {object}  models.ErrorFarmerResponse  "INVALID_BODY"
+// @Failure      404       {object}  models.ErrorFarmerResponse  "FARMER_NOT_FOUND or INVALID_COOP"
+// @Failure      409       {object}  models.ErrorFarmerResponse  "DUPLICATE_KYC"
+// @Failure      422       {object}  models.ErrorFarmerResponse  "VALIDATION_ERROR, FARMER_ID_IMMUTABLE, INVALID_KYC, INVALID_GEOGRAPHY or INVALID_CLUB_LEADER"
+// @Failure      502       {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /spic_to_erp/vendors/{coopId}/farmers/{farmerId} [patch]
+func (h *FarmerController) UpdateVendorDetailHandler(c *fiber.Ctx) error {
+	return h.updateFarmer(c, models.RoleVendor)
+}
 
-	totalPages := int(math.Ceil(float64(totalRecords) / float64(limit)))
+// DeleteCustomerDetailHandler handles DELETE /spic_to_erp/customers/:coopId/farmers/:farmerId
+// @Summary      Delete a customer farmer
+// @Description  Soft-delete the farmer. It disappears from customer and vendor lookups until restored.
+// @Tags         Details
+// @Produce      json
+// @Param        coopId    path      string  true  "Cooperative ID"
+// @Param        farmerId  path      string  true  "Farmer ID"
+// @Success      200       {object}  models.CreateSuccessFarmerResponse
+// @Failure      404       {object}  models.ErrorFarmerResponse  "FARMER_NOT_FOUND or INVALID_COOP"
+// @Failure      502       {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /spic_to_erp/customers/{coopId}/farmers/{farmerId} [delete]
+func (h *FarmerController) DeleteCustomerDetailHandler(c *fiber.Ctx) error {
+	return h.deleteFarmer(c, models.RoleCustomer)
+}
 
-	// ✅ Map DB → RESPONSE MODEL
-	var data []models.FarmerResponse
-	for _, f := range farmers {
-		data = append(data, models.FarmerResponse{
-			ErpCustomerId:     f.CustomerID,
-			TempERPCustomerID: f.TempID,
-			ErpVendorId:       f.VendorID,
-			// TempErpVendorId:   f.TempVendorID, // if exists
-			FarmerId:  f.FarmerID,
-			CreatedAt: f.CreatedAt.Format("2006-01-02T15:04:05Z"),
-			UpdatedAt: f.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-		})
-	}
+// DeleteVendorDetailHandler handles DELETE /spic_to_erp/vendors/:coopId/farmers/:farmerId
+// @Summary      Delete a vendor farmer
+// @Description  Soft-delete the farmer. It disappears from customer and vendor lookups until restored.
+// @Tags         Details
+// @Produce      json
+// @Param        coopId    path      string  true  "Cooperative ID"
+// @Param        farmerId  path      string  true  "Farmer ID"
+// @Success      200       {object}  models.CreateSuccessFarmerResponse
+// @Failure      404       {object}  models.ErrorFarmerResponse  "FARMER_NOT_FOUND or INVALID_COOP"
+// @Failure      502       {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /spic_to_erp/vendors/{coopId}/farmers/{farmerId} [delete]
+func (h *FarmerController) DeleteVendorDetailHandler(c *fiber.Ctx) error {
+	return h.deleteFarmer(c, models.RoleVendor)
+}
 
-	return c.Status(fiber.StatusOK).JSON(models.ListFarmersResponse{
-		Data: data,
-		Pagination: models.PaginationInfo{
-			Page:        page,
-			Limit:       limit,
-			TotalItems:  int(totalRecords),
-			TotalPages:  totalPages,
-			HasPrevious: page > 1,
-			HasNext:     page < totalPages,
-		},
-	})
+// RestoreCustomerDetailHandler handles POST /spic_to_erp/customers/:coopId/farmers/:farmerId/restore
+// @Summary      Restore a deleted customer farmer
+// @Tags         Details
+// @Produce      json
+// @Param        coopId    path      string  true  "Cooperative ID"
+// @Param        farmerId  path      string  true  "Farmer ID"
+// @Success      200       {object}  models.CreateSuccessFarmerResponse
+// @Failure      404       {object}  models.ErrorFarmerResponse  "FARMER_NOT_FOUND or INVALID_COOP"
+// @Failure      409       {object}  models.ErrorFarmerResponse  "DUPLICATE_FARMER or DUPLICATE_KYC"
+// @Failure      502       {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /spic_to_erp/customers/{coopId}/farmers/{farmerId}/restore [post]
+func (h *FarmerController) RestoreCustomerDetailHandler(c *fiber.Ctx) error {
+	return h.restoreFarmer(c, models.RoleCustomer)
 }
 
-// CreateVendorDetailHandler handles POST /spic_to_erp/vendors/:coopId/farmers
-// @Summary      Create a new farmer detail
-// @Description  Create a new record in the details table
+// RestoreVendorDetailHandler handles POST /spic_to_erp/vendors/:coopId/farmers/:farmerId/restore
+// @Summary      Restore a deleted vendor farmer
 // @Tags         Details
-// @Accept       json
 // @Produce      json
-// @Param        coopId  path      string                            true  "Cooperative ID"
-// @Param        detail  body      models.CreateDetailSchema          true  "Create Detail Payload"
-// @Success      201     {object}  models.CreateSuccessFarmerResponse
-// @Router       /spic_to_erp/vendors/{coopId}/farmers [post]
-func CreateVendorDetailHandler(c *fiber.Ctx) error {
+// @Param        coopId    path      string  true  "Cooperative ID"
+// @Param        farmerId  path      string  true  "Farmer ID"
+// @Success      200       {object}  models.CreateSuccessFarmerResponse
+// @Failure      404       {object}  models.ErrorFarmerResponse  "FARMER_NOT_FOUND or INVALID_COOP"
+// @Failure      409       {object}  models.ErrorFarmerResponse  "DUPLICATE_FARMER or DUPLICATE_KYC"
+// @Failure      502       {object}  models.ErrorFarmerResponse  "STORAGE_ERROR"
+// @Router       /spic_to_erp/vendors/{coopId}/farmers/{farmerId}/restore [post]
+func (h *FarmerController) RestoreVendorDetailHandler(c *fiber.Ctx) error {
+	return h.restoreFarmer(c, models.RoleVendor)
+}
+
+// registerFarmer registers the farmer in the given role. A farmer already
+// known in the cooperative under the other role keeps its record and simply
+// gains the new role, with its own temp ID and approval lifecycle.
+func (h *FarmerController) registerFarmer(c *fiber.Ctx, role string) error {
 	// 1. Get CoopID from URL Parameter
 	coopId := c.Params("coopId")
 	var payload *models.CreateDetailSchema
-	var existingFarmer models.FarmerDetails
 
 	// 2. Parse the JSON Body
 	if err := c.BodyParser(&payload); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"status": "fail", "message": err.Error()})
+		return SendErrorResponse(c, invalidBodyError(err), "")
+	}
+	if payload == nil {
+		return SendErrorResponse(c, invalidBodyError(errNullBody), "")
 	}
 
-	//3. Constraints for the payload check
-	if payload.FarmerID == "" {
-		return SendCustomerErrorResponse(c, "You must provide a Farmer ID.", payload.FarmerID)
+	//3. Constraints and duplicate checks
+	farmer, isNew, err := h.prepareRegistration(h.repos, coopId, payload, role, time.Now().UTC())
+	if err != nil {
+		return SendErrorResponse(c, err, payload.FarmerID)
 	}
 
-	if payload.FirstName == "" || payload.LastName == "" {
-		return SendCustomerErrorResponse(c, "You must provide the first and last name.", payload.FarmerID)
+	// 4. Save to Database (GORM fills in CreatedAt/UpdatedAt here)
+	if err := h.saveWithClub(farmer, role, isNew); err != nil {
+		return SendErrorResponse(c, err, payload.FarmerID)
 	}
 
-	if payload.FarmerKycID == "" && payload.ClubLeaderFarmerID == "" {
-		return SendCustomerErrorResponse(c, "Either farmer_kyc_id or clubLeaderFarmerId must be provided.", payload.FarmerID)
+	return c.Status(fiber.StatusCreated).JSON(models.CreateSuccessFarmerResponse{
+		Success: true,
+		Data:    farmerResponse(farmer, role, "Farmer detail created successfully"),
+	})
+}
+
+// prepareRegistration validates the payload against the farmers and clubs of
+// repos and returns the farmer to save: a new record, or the existing farmer
+// of the cooperative with the role added. Rejections are returned as an
+// apiError.
+func (h *FarmerController) prepareRegistration(repos *repository.Repositories, coopId string, payload *models.CreateDetailSchema, role string, now time.Time) (farmer *models.FarmerDetails, isNew bool, err error) {
+	if err := validateDetail(payload, nil); err != nil {
+		return nil, false, err
+	}
+
+	repo := repos.Farmers
+	existing, err := repo.FindByCoopAndFarmer(coopId, payload.FarmerID)
+	if errors.Is(err, repository.ErrFarmerNotFound) {
+		existing = nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	kycOwner, err := repo.FindByKYC(payload.FarmerKycID)
+	if err == nil && (existing == nil || kycOwner.ID != existing.ID) {
+		return nil, false, duplicateKYCError(payload.FarmerKycID)
+	}
+	if err != nil && !errors.Is(err, repository.ErrFarmerNotFound) {
+		return nil, false, err
+	}
+
+	if existing != nil && existing.HasRole(role) {
+		return nil, false, duplicateFarmerError(payload.FarmerID, coopId)
+	}
+
+	// Known farmer: add the new role to the existing record. Only the role is
+	// written, so details that differ are rejected rather than dropped; the
+	// stored details already passed validation.
+	if existing != nil {
+		stored := *existing
+		changed, err := applyFarmerPatch(&stored, payload, allPatchFields)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(changed) > 0 {
+			return nil, false, detailsMismatchError(payload.FarmerID, changed)
+		}
+		existing.AddRole(role, now)
+		return existing, false, nil
 	}
 
-	kycId := initializers.DB.Where("farmer_kyc_id = ?", payload.FarmerKycID).First(&existingFarmer).Error
-	if kycId == nil {
-		return SendCustomerErrorResponse(c, "Farmer with the given KYC ID "+payload.FarmerKycID+" already exists.", payload.FarmerID)
+	if err := h.checkKyc(payload.FarmerKycTypeID, payload.FarmerKycType, payload.FarmerKycID); err != nil {
+		return nil, false, err
 	}
 
-	if coopId == "" {
-		return SendCustomerErrorResponse(c, "The indicated cooperative does not exist.", payload.FarmerID)
+	if err := h.checkGeography(payload.RegionID, payload.RegionPartID, payload.SettlementID, payload.SettlementPartID, payload.CustomGeo1ID, payload.CustomGeo2ID, payload.ZipCode); err != nil {
+		return nil, false, err
 	}
 
-	farmerId := initializers.DB.Where("farmer_id = ? AND coop_id = ?", payload.FarmerID, coopId).First(&existingFarmer).Error
-	if farmerId == nil {
-		return SendCustomerErrorResponse(c, "The Farmer ID "+payload.FarmerID+" is already registered in the cooperative "+coopId+".", payload.FarmerID)
+	if err := checkClubLeader(repo, repos.Clubs, coopId, payload.FarmerID, payload.FarmerKycID, payload.ClubID, payload.ClubLeaderFarmerID); err != nil {
+		return nil, false, err
 	}
 
-	// 4. Map everything to the DB Model
+	// Map everything to the DB Model
 	newDetail := models.FarmerDetails{
 		CoopID:                      coopId, // Set from URL Param
 		FarmerID:                    payload.FarmerID,
@@ -260,184 +430,549 @@ func CreateVendorDetailHandler(c *fiber.Ctx) error {
 		ClubName:                    payload.ClubName,
 		ClubLeaderFarmerID:          payload.ClubLeaderFarmerID,
 	}
+	// Both dates already passed the RFC 3339 check
+	newDetail.RaithuCreatedDate, _ = parseISODate(payload.RaithuCreatedDate)
+	newDetail.RaithuUpdatedAt, _ = parseISODate(payload.RaithuUpdatedAt)
+	newDetail.AddRole(role, now)
+	return &newDetail, true, nil
+}
 
-	// 5. Save to Database (GORM fills in CreatedAt/UpdatedAt here)
-	result := initializers.DB.Create(&newDetail)
-	if result.Error != nil {
-		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"status": "error", "message": result.Error.Error()})
-	}
+// saveWithClub saves the registration and records its club in one
+// transaction, so a farmer is never stored without its club
+func (h *FarmerController) saveWithClub(farmer *models.FarmerDetails, role string, isNew bool) error {
+	return h.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := saveRegistration(tx.Farmers, farmer, role, isNew); err != nil {
+			return err
+		}
+		return syncClub(tx.Clubs, farmer)
+	})
+}
 
-	// Inside CreateCustomerDetailHandler...
+// saveRegistration creates a new farmer, or writes the role added to an
+// existing one only if no concurrent request registered that role first
+func saveRegistration(repo repository.FarmerRepository, farmer *models.FarmerDetails, role string, isNew bool) error {
+	if isNew {
+		return duplicateError(repo.Create(farmer), farmer)
+	}
+	return duplicateError(repo.UpdateRole(farmer, role, ""), farmer)
+}
 
-	response := models.CreateSuccessFarmerResponse{
-		Success: true,
-		Data: models.FarmerResponse{
-			TempERPCustomerID: newDetail.TempID,
-			ErpCustomerId:     newDetail.CustomerID,
-			ErpVendorId:       newDetail.VendorID,
-			FarmerId:          newDetail.FarmerID,
-			CreatedAt:         newDetail.CreatedAt.Format("2006-01-02T15:04:05Z"),
-			UpdatedAt:         newDetail.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-			Message:           "Farmer detail created successfully",
-		},
+// duplicateError turns a unique key violation or a lost conditional role
+// write caught by the storage, which the lookups before a write can miss
+// under concurrent requests, into the same error those lookups return
+func duplicateError(err error, farmer *models.FarmerDetails) error {
+	switch {
+	case errors.Is(err, repository.ErrDuplicateFarmer), errors.Is(err, repository.ErrRoleChanged):
+		return duplicateFarmerError(farmer.FarmerID, farmer.CoopID)
+	case errors.Is(err, repository.ErrDuplicateKYC):
+		return duplicateKYCError(farmer.FarmerKycID)
 	}
+	return err
+}
 
-	return c.Status(fiber.StatusCreated).JSON(response)
+// checkKyc rejects unknown or mismatched KYC types and KYC IDs that do not
+// match the format of their type
+func (h *FarmerController) checkKyc(typeID int, typeName string, kycID string) error {
+	fields := h.kycTypes.Validate(typeID, typeName, kycID)
+	if len(fields) == 0 {
+		return nil
+	}
+	err := validationError(fields)
+	err.Code = models.ErrCodeInvalidKyc
+	return err
+}
 
+// checkGeography rejects addresses that do not match the geography master data
+func (h *FarmerController) checkGeography(regionID, regionPartID, settlementID, settlementPartID int, customGeo1ID, customGeo2ID, zipCode string) error {
+	fields := h.geography.Validate(services.Address{
+		RegionID:         regionID,
+		RegionPartID:     regionPartID,
+		SettlementID:     settlementID,
+		SettlementPartID: settlementPartID,
+		CustomGeo1ID:     customGeo1ID,
+		CustomGeo2ID:     customGeo2ID,
+		ZipCode:          zipCode,
+	})
+	if len(fields) == 0 {
+		return nil
+	}
+	err := validationError(fields)
+	err.Code = models.ErrCodeInvalidGeography
+	return err
 }
 
-// FindDetails handles GET /spic_to_erp/vendors/:coopId/farmers
-// @Summary      List farmer details
-// @Description  Get a paginated list of farmer details for a specific cooperative
-// @Tags         Details
-// @Accept       json
-// @Produce      json
-// @Param        coopId path      string  true   " "
-// @Param        updatedFrom   query     string  false  " "
-// @Param        updatedTo     query     string  false  " "
-// @Param        page          query     int     false  "Page number"    default(1)
-// @Param        limit         query     int     false  "Items per page" default(10)
-// @Success      200    {object}  models.ListFarmersResponse
-// @Router       /spic_to_erp/vendors/{coopId}/farmers [get]
-func FindVendorDetailsHandler(c *fiber.Ctx) error {
-	coopId := c.Params("coopId")
+// checkClubLeader enforces the ERP rule that a club leader is a registered
+// farmer of the same cooperative and, once the club is known, its leader.
+// A farmer may only name themselves as leader when they have their own KYC ID.
+func checkClubLeader(repo repository.FarmerRepository, clubs repository.ClubRepository, coopId, farmerId, kycId, clubId, leaderId string) error {
+	if leaderId == "" {
+		return nil
+	}
+
+	if leaderId == farmerId {
+		if kycId == "" {
+			return newAPIError(fiber.StatusUnprocessableEntity, models.ErrCodeClubLeader, "A farmer without a KYC ID cannot be their own club leader.")
+		}
+	} else if _, err := repo.FindByCoopAndFarmer(coopId, leaderId); errors.Is(err, repository.ErrFarmerNotFound) {
+		return newAPIError(fiber.StatusUnprocessableEntity, models.ErrCodeClubLeader, "The club leader "+leaderId+" is not a registered farmer of the cooperative "+coopId+".")
+	} else if err != nil {
+		return err
+	}
+
+	if clubId == "" {
+		return nil
+	}
+	club, err := clubs.Find(coopId, clubId)
+	if errors.Is(err, repository.ErrClubNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if club.LeaderFarmerID != "" && club.LeaderFarmerID != leaderId {
+		return newAPIError(fiber.StatusUnprocessableEntity, models.ErrCodeClubLeader, "The club "+clubId+" is led by "+club.LeaderFarmerID+", not "+leaderId+".")
+	}
+	return nil
+}
 
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-	offset := (page - 1) * limit
+// syncClub records the club of a saved farmer. The first member registered
+// with a club ID creates the club; its name and leader are filled in as
+// members provide them.
+func syncClub(clubs repository.ClubRepository, farmer *models.FarmerDetails) error {
+	if farmer.ClubID == "" {
+		return nil
+	}
 
-	var farmers []models.FarmerDetails
-	var totalRecords int64
+	club, err := clubs.Find(farmer.CoopID, farmer.ClubID)
+	if errors.Is(err, repository.ErrClubNotFound) {
+		club = &models.Club{CoopID: farmer.CoopID, ID: farmer.ClubID, CreatedAt: farmer.UpdatedAt}
+	} else if err != nil {
+		return err
+	}
 
-	query := initializers.DB.
-		Model(&models.FarmerDetails{}).
-		Where("coop_id = ?", coopId)
+	changed := club.UpdatedAt == nil
+	if club.Name == "" && farmer.ClubName != "" {
+		club.Name = farmer.ClubName
+		changed = true
+	}
+	if club.LeaderFarmerID == "" && farmer.ClubLeaderFarmerID != "" {
+		club.LeaderFarmerID = farmer.ClubLeaderFarmerID
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	club.UpdatedAt = farmer.UpdatedAt
+	return clubs.Save(club)
+}
 
-	query.Count(&totalRecords)
+func (h *FarmerController) listFarmers(c *fiber.Ctx, role string) error {
+	coopId := c.Params("coopId")
 
-	if err := query.
-		Limit(limit).
-		Offset(offset).
-		Find(&farmers).Error; err != nil {
+	page, limit := parsePage(c)
 
-		return c.Status(fiber.StatusBadGateway).JSON(models.ErrorFarmerResponse{
-			Success: false,
-			Message: err.Error(),
-		})
+	updatedFrom, updatedTo, err := parseUpdatedRange(c)
+	if err != nil {
+		return SendErrorResponse(c, newAPIError(fiber.StatusBadRequest, models.ErrCodeInvalidQuery, err.Error()), "")
 	}
 
-	totalPages := int(math.Ceil(float64(totalRecords) / float64(limit)))
+	farmers, totalRecords, err := h.repo.List(repository.FarmerFilter{
+		CoopID:      coopId,
+		Role:        role,
+		UpdatedFrom: updatedFrom,
+		UpdatedTo:   updatedTo,
+		WithDeleted: c.QueryBool("includeDeleted"),
+		Limit:       limit,
+		Offset:      (page - 1) * limit,
+	})
+	if err != nil {
+		return SendErrorResponse(c, err, "")
+	}
 
 	// ✅ Map DB → RESPONSE MODEL
 	var data []models.FarmerResponse
-	for _, f := range farmers {
-		data = append(data, models.FarmerResponse{
-			ErpCustomerId:     f.CustomerID,
-			TempERPCustomerID: f.TempID,
-			ErpVendorId:       f.VendorID,
-			// TempErpVendorId:   f.TempVendorID, // if exists
-			FarmerId:  f.FarmerID,
-			CreatedAt: f.CreatedAt.Format("2006-01-02T15:04:05Z"),
-			UpdatedAt: f.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-		})
+	for i := range farmers {
+		data = append(data, farmerResponse(&farmers[i], role, ""))
 	}
 
 	return c.Status(fiber.StatusOK).JSON(models.ListFarmersResponse{
-		Data: data,
-		Pagination: models.PaginationInfo{
-			Page:        page,
-			Limit:       limit,
-			TotalItems:  int(totalRecords),
-			TotalPages:  totalPages,
-			HasPrevious: page > 1,
-			HasNext:     page < totalPages,
-		},
+		Data:       data,
+		Pagination: pagination(page, limit, totalRecords),
 	})
 }
 
-// FindDetails handles GET /spic_to_erp/customers/:coopId/farmers/:farmerId
-// @Summary      List farmer details
-// @Description  Get a paginated list of farmer details for a specific cooperative
-// @Tags         Details
-// @Accept       json
-// @Produce      json
-// @Param        coopId path      string  true   " "
-// @Param        farmerId path      string  true   " "
-// @Success      200    {object}  models.FarmerDetailResponse
-// @Router       /spic_to_erp/customers/{coopId}/farmers/{farmerId} [get]
-func GetCustomerDetailHandler(c *fiber.Ctx) error {
+func (h *FarmerController) listClubFarmers(c *fiber.Ctx, role string) error {
 	coopId := c.Params("coopId")
-	farmerId := c.Params("farmerId")
+	clubId := c.Params("clubId")
+	page, limit := parsePage(c)
+
+	club, err := h.clubs.Find(coopId, clubId)
+	if errors.Is(err, repository.ErrClubNotFound) {
+		return SendErrorResponse(c, newAPIError(fiber.StatusNotFound, models.ErrCodeClubNotFound, "The club "+clubId+" does not exist in the cooperative "+coopId+"."), "")
+	}
+	if err != nil {
+		return SendErrorResponse(c, err, "")
+	}
+
+	farmers, totalRecords, err := h.repo.List(repository.FarmerFilter{
+		CoopID: coopId,
+		ClubID: clubId,
+		Role:   role,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		return SendErrorResponse(c, err, "")
+	}
 
-	var farmer models.FarmerDetails
+	var data []models.FarmerResponse
+	for i := range farmers {
+		data = append(data, farmerResponse(&farmers[i], role, ""))
+	}
 
-	err := initializers.DB.
-		Where("coop_id = ? AND farmer_id = ?", coopId, farmerId).
-		First(&farmer).Error
+	return c.Status(fiber.StatusOK).JSON(models.ClubFarmersResponse{
+		Club:       *club,
+		Data:       data,
+		Pagination: pagination(page, limit, totalRecords),
+	})
+}
 
+// parsePage reads the page and limit query parameters, defaulting to the
+// first page of 10
+func parsePage(c *fiber.Ctx) (page int, limit int) {
+	page, _ = strconv.Atoi(c.Query("page", "1"))
+	limit, _ = strconv.Atoi(c.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	return page, limit
+}
+
+func pagination(page int, limit int, totalRecords int64) models.PaginationInfo {
+	totalPages := int(math.Ceil(float64(totalRecords) / float64(limit)))
+	return models.PaginationInfo{
+		Page:        page,
+		Limit:       limit,
+		TotalItems:  int(totalRecords),
+		TotalPages:  totalPages,
+		HasPrevious: page > 1,
+		HasNext:     page < totalPages,
+	}
+}
+
+// findWithRole looks the farmer up with find and answers FARMER_NOT_FOUND
+// with message when there is none or it lacks the role. Storage failures are
+// returned as they are.
+func findWithRole(find func(coopId, farmerId string) (*models.FarmerDetails, error), coopId, farmerId, role, message string) (*models.FarmerDetails, error) {
+	farmer, err := find(coopId, farmerId)
+	if errors.Is(err, repository.ErrFarmerNotFound) || err == nil && !farmer.HasRole(role) {
+		return nil, farmerNotFoundError(message)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(models.ErrorFarmerResponse{
-			Success: false,
-			Message: "Farmer not found",
-		})
+		return nil, err
 	}
+	return farmer, nil
+}
 
-	response := models.FarmerDetailResponse{
-		FarmerID:           farmer.FarmerID,
-		Name:               farmer.FirstName + " " + farmer.LastName,
-		MobileNumber:       farmer.MobileNumber,
-		Cooperative:        farmer.CoopID,
-		SettlementID:       farmer.SettlementID,
-		SettlementPartID:   farmer.SettlementPartID,
-		ZipCode:            farmer.ZipCode,
-		FarmerKycTypeID:    farmer.FarmerKycTypeID,
-		FarmerKycType:      farmer.FarmerKycType,
-		FarmerKycID:        farmer.FarmerKycID,
-		ClubID:             farmer.ClubID,
-		ClubLeaderFarmerID: farmer.ClubLeaderFarmerID,
-		Message:            "Farmer detail fetched successfully",
-		EntityID:           farmer.TempID, // or permanent entity ID
-		CustomerCode:       farmer.CustomerID,
-		VendorCode:         farmer.VendorID,
-		CreatedDate:        farmer.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		UpdatedDate:        farmer.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-		// BankDetails: models.BankDetailsInfo{
-		// 	IBAN:  farmer.IBAN,   // ensure field exists
-		// 	SWIFT: farmer.SWIFT,  // ensure field exists
-		// },
+func (h *FarmerController) getFarmer(c *fiber.Ctx, role string) error {
+	coopId := c.Params("coopId")
+	farmerId := c.Params("farmerId")
+
+	farmer, err := findWithRole(h.repo.FindByCoopAndFarmer, coopId, farmerId, role, "Farmer not found")
+	if err != nil {
+		return SendErrorResponse(c, err, farmerId)
 	}
 
-	return c.Status(fiber.StatusOK).JSON(response)
+	return c.Status(fiber.StatusOK).JSON(farmerDetailResponse(farmer, role))
 }
 
-// FindDetails handles GET /spic_to_erp/vendors/:coopId/farmers/:farmerId
-// @Summary      List farmer details
-// @Description  Get a paginated list of farmer details for a specific cooperative
-// @Tags         Details
-// @Accept       json
-// @Produce      json
-// @Param        coopId path      string  true   " "
-// @Param        farmerId path      string  true   " "
-// @Success      200    {object}  models.FarmerDetailResponse
-// @Router       /spic_to_erp/vendors/{coopId}/farmers/{farmerId} [get]
-func GetVendorDetailHandler(c *fiber.Ctx) error {
+func (h *FarmerController) updateFarmer(c *fiber.Ctx, role string) error {
 	coopId := c.Params("coopId")
 	farmerId := c.Params("farmerId")
 
-	var farmer models.FarmerDetails
+	farmer, err := findWithRole(h.repo.FindByCoopAndFarmer, coopId, farmerId, role, "Farmer not found")
+	if err != nil {
+		return SendErrorResponse(c, err, farmerId)
+	}
+
+	// The raw keys tell which fields the client actually sent
+	var present map[string]json.RawMessage
+	var payload models.CreateDetailSchema
+	if err := json.Unmarshal(c.Body(), &present); err != nil {
+		return SendErrorResponse(c, invalidBodyError(err), farmer.FarmerID)
+	}
+	if present == nil {
+		return SendErrorResponse(c, invalidBodyError(errNullBody), farmer.FarmerID)
+	}
+	if err := json.Unmarshal(c.Body(), &payload); err != nil {
+		return SendErrorResponse(c, invalidBodyError(err), farmer.FarmerID)
+	}
+
+	if _, ok := present["farmerId"]; ok && payload.FarmerID != farmer.FarmerID {
+		return SendErrorResponse(c, newAPIError(fiber.StatusUnprocessableEntity, models.ErrCodeFarmerIDImmutable, "The Farmer ID cannot be changed."), farmer.FarmerID)
+	}
 
-	err := initializers.DB.
-		Where("coop_id = ? AND farmer_id = ?", coopId, farmerId).
-		First(&farmer).Error
+	// Only the fields sent are checked. Whether the farmer still has a KYC ID
+	// or club leader depends on the stored record, so that rule runs after
+	// the patch is applied.
+	err = validateDetail(&payload, func(field *models.ErrorResponse) bool {
+		_, ok := present[field.Field]
+		return ok && field.Tag != "required_without"
+	})
+	if err != nil {
+		return SendErrorResponse(c, err, farmer.FarmerID)
+	}
 
+	changed, err := applyFarmerPatch(farmer, &payload, present)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(models.ErrorFarmerResponse{
-			Success: false,
-			Message: "Farmer not found",
+		return SendErrorResponse(c, err, farmer.FarmerID)
+	}
+
+	if farmer.FarmerKycID == "" && farmer.ClubLeaderFarmerID == "" {
+		return SendErrorResponse(c, validationError([]*models.ErrorResponse{{
+			Field:   "farmer_kyc_id",
+			Tag:     "required_without",
+			Message: "Either farmer_kyc_id or clubLeaderFarmerId must be provided",
+		}}), farmer.FarmerID)
+	}
+
+	if kycOwner, err := h.repo.FindByKYC(farmer.FarmerKycID); err == nil && kycOwner.ID != farmer.ID {
+		return SendErrorResponse(c, duplicateKYCError(farmer.FarmerKycID), farmer.FarmerID)
+	} else if err != nil && !errors.Is(err, repository.ErrFarmerNotFound) {
+		return SendErrorResponse(c, err, farmer.FarmerID)
+	}
+
+	if slices.Contains(changed, "farmer_kyc_type_id") || slices.Contains(changed, "farmer_kyc_type") || slices.Contains(changed, "farmer_kyc_id") {
+		if err := h.checkKyc(farmer.FarmerKycTypeID, farmer.FarmerKycType, farmer.FarmerKycID); err != nil {
+			return SendErrorResponse(c, err, farmer.FarmerID)
+		}
+	}
+
+	if slices.ContainsFunc(changed, isGeographyField) {
+		if err := h.checkGeography(farmer.RegionID, farmer.RegionPartID, farmer.SettlementID, farmer.SettlementPartID, farmer.CustomGeographyStructure1ID, farmer.CustomGeographyStructure2ID, farmer.ZipCode); err != nil {
+			return SendErrorResponse(c, err, farmer.FarmerID)
+		}
+	}
+
+	if slices.Contains(changed, "clubId") || slices.Contains(changed, "clubLeaderFarmerId") || slices.Contains(changed, "farmer_kyc_id") {
+		if err := checkClubLeader(h.repo, h.clubs, coopId, farmer.FarmerID, farmer.FarmerKycID, farmer.ClubID, farmer.ClubLeaderFarmerID); err != nil {
+			return SendErrorResponse(c, err, farmer.FarmerID)
+		}
+	}
+
+	if len(changed) == 0 {
+		return c.Status(fiber.StatusOK).JSON(models.CreateSuccessFarmerResponse{
+			Success: true,
+			Data:    farmerResponse(farmer, role, "No changes to the farmer detail"),
 		})
 	}
 
-	response := models.FarmerDetailResponse{
+	// Bumping UpdatedAt makes the change visible to updatedFrom list queries.
+	// Only the changed columns are written, so an ERP approval or a role
+	// added since the farmer was read is kept.
+	fields := make([]string, len(changed))
+	for i, key := range changed {
+		fields[i] = patchFields[key]
+	}
+	now := time.Now().UTC()
+	farmer.UpdatedAt = &now
+	err = h.repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Farmers.UpdateFields(farmer, fields); err != nil {
+			return duplicateError(err, farmer)
+		}
+		return syncClub(tx.Clubs, farmer)
+	})
+	if errors.Is(err, repository.ErrFarmerNotFound) {
+		return SendErrorResponse(c, farmerNotFoundError("Farmer not found"), farmer.FarmerID)
+	}
+	if err != nil {
+		return SendErrorResponse(c, err, farmer.FarmerID)
+	}
+
+	response := farmerResponse(farmer, role, "Farmer detail updated successfully")
+	response.ChangedFields = changed
+	return c.Status(fiber.StatusOK).JSON(models.CreateSuccessFarmerResponse{
+		Success: true,
+		Data:    response,
+	})
+}
+
+func (h *FarmerController) deleteFarmer(c *fiber.Ctx, role string) error {
+	farmer, err := findWithRole(h.repo.FindByCoopAndFarmer, c.Params("coopId"), c.Params("farmerId"), role, "Farmer not found")
+	if err != nil {
+		return SendErrorResponse(c, err, c.Params("farmerId"))
+	}
+
+	if err := h.repo.Delete(farmer); errors.Is(err, repository.ErrFarmerNotFound) {
+		return SendErrorResponse(c, farmerNotFoundError("Farmer not found"), farmer.FarmerID)
+	} else if err != nil {
+		return SendErrorResponse(c, err, farmer.FarmerID)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.CreateSuccessFarmerResponse{
+		Success: true,
+		Data:    farmerResponse(farmer, role, "Farmer detail deleted successfully"),
+	})
+}
+
+func (h *FarmerController) restoreFarmer(c *fiber.Ctx, role string) error {
+	coopId := c.Params("coopId")
+	farmerId := c.Params("farmerId")
+
+	farmer, err := findWithRole(h.repo.FindDeleted, coopId, farmerId, role, "Deleted farmer not found")
+	if err != nil {
+		return SendErrorResponse(c, err, farmerId)
+	}
+
+	// The farmer ID or KYC ID may have been registered again since the delete
+	if _, err := h.repo.FindByCoopAndFarmer(coopId, farmerId); err == nil {
+		return SendErrorResponse(c, duplicateFarmerError(farmerId, coopId), farmerId)
+	} else if !errors.Is(err, repository.ErrFarmerNotFound) {
+		return SendErrorResponse(c, err, farmerId)
+	}
+	if _, err := h.repo.FindByKYC(farmer.FarmerKycID); err == nil {
+		return SendErrorResponse(c, duplicateKYCError(farmer.FarmerKycID), farmerId)
+	} else if !errors.Is(err, repository.ErrFarmerNotFound) {
+		return SendErrorResponse(c, err, farmerId)
+	}
+
+	now := time.Now().UTC()
+	farmer.UpdatedAt = &now
+	if err := h.repo.Restore(farmer); err != nil {
+		return SendErrorResponse(c, duplicateError(err, farmer), farmer.FarmerID)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.CreateSuccessFarmerResponse{
+		Success: true,
+		Data:    farmerResponse(farmer, role, "Farmer detail restored successfully"),
+	})
+}
+
+// patchFields maps the JSON names reported by applyFarmerPatch to the
+// FarmerDetails fields they are stored in
+var patchFields = map[string]string{
+	"firstName":                      "FirstName",
+	"lastName":                       "LastName",
+	"mobile_number":                  "MobileNumber",
+	"regionId":                       "RegionID",
+	"regionPartID":                   "RegionPartID",
+	"settlementID":                   "SettlementID",
+	"settlementPartID":               "SettlementPartID",
+	"custom_geography_structure1_id": "CustomGeographyStructure1ID",
+	"custom_geography_structure2_id": "CustomGeographyStructure2ID",
+	"ZipCode":                        "ZipCode",
+	"farmer_kyc_type_id":             "FarmerKycTypeID",
+	"farmer_kyc_type":                "FarmerKycType",
+	"farmer_kyc_id":                  "FarmerKycID",
+	"clubId":                         "ClubID",
+	"clubName":                       "ClubName",
+	"clubLeaderFarmerId":             "ClubLeaderFarmerID",
+	"raithuCreatedDate":              "RaithuCreatedDate",
+	"raithuUpdatedAt":                "RaithuUpdatedAt",
+}
+
+// allPatchFields marks every patchable field as sent, so applyFarmerPatch
+// compares a whole payload with the stored farmer
+var allPatchFields = func() map[string]json.RawMessage {
+	present := make(map[string]json.RawMessage, len(patchFields))
+	for key := range patchFields {
+		present[key] = nil
+	}
+	return present
+}()
+
+func isGeographyField(key string) bool {
+	switch key {
+	case "regionId", "regionPartID", "settlementID", "settlementPartID",
+		"custom_geography_structure1_id", "custom_geography_structure2_id", "ZipCode":
+		return true
+	}
+	return false
+}
+
+// applyFarmerPatch copies the fields present in a PATCH body onto the farmer
+// and returns the JSON names of the fields whose value actually changed
+func applyFarmerPatch(f *models.FarmerDetails, p *models.CreateDetailSchema, present map[string]json.RawMessage) ([]string, error) {
+	var changed []string
+	setString := func(key string, dst *string, value string) {
+		if _, ok := present[key]; ok && *dst != value {
+			*dst = value
+			changed = append(changed, key)
+		}
+	}
+	setInt := func(key string, dst *int, value int) {
+		if _, ok := present[key]; ok && *dst != value {
+			*dst = value
+			changed = append(changed, key)
+		}
+	}
+	setTime := func(key string, dst **time.Time, value string) error {
+		if _, ok := present[key]; !ok {
+			return nil
+		}
+		t, err := parseISODate(value)
+		if err != nil {
+			return newAPIError(fiber.StatusUnprocessableEntity, models.ErrCodeValidation, fmt.Sprintf("invalid %s: %s", key, err))
+		}
+		if (*dst == nil) != (t == nil) || (t != nil && !t.Equal(**dst)) {
+			*dst = t
+			changed = append(changed, key)
+		}
+		return nil
+	}
+
+	setString("firstName", &f.FirstName, p.FirstName)
+	setString("lastName", &f.LastName, p.LastName)
+	setString("mobile_number", &f.MobileNumber, p.MobileNumber)
+	setInt("regionId", &f.RegionID, p.RegionID)
+	setInt("regionPartID", &f.RegionPartID, p.RegionPartID)
+	setInt("settlementID", &f.SettlementID, p.SettlementID)
+	setInt("settlementPartID", &f.SettlementPartID, p.SettlementPartID)
+	setString("custom_geography_structure1_id", &f.CustomGeographyStructure1ID, p.CustomGeo1ID)
+	setString("custom_geography_structure2_id", &f.CustomGeographyStructure2ID, p.CustomGeo2ID)
+	setString("ZipCode", &f.ZipCode, p.ZipCode)
+	setInt("farmer_kyc_type_id", &f.FarmerKycTypeID, p.FarmerKycTypeID)
+	setString("farmer_kyc_type", &f.FarmerKycType, p.FarmerKycType)
+	setString("farmer_kyc_id", &f.FarmerKycID, p.FarmerKycID)
+	setString("clubId", &f.ClubID, p.ClubID)
+	setString("clubName", &f.ClubName, p.ClubName)
+	setString("clubLeaderFarmerId", &f.ClubLeaderFarmerID, p.ClubLeaderFarmerID)
+	if err := setTime("raithuCreatedDate", &f.RaithuCreatedDate, p.RaithuCreatedDate); err != nil {
+		return nil, err
+	}
+	if err := setTime("raithuUpdatedAt", &f.RaithuUpdatedAt, p.RaithuUpdatedAt); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// farmerResponse maps a farmer to the ERP response, with the status and
+// creation time of the requested role
+func farmerResponse(f *models.FarmerDetails, role string, message string) models.FarmerResponse {
+	var deletedAt string
+	if f.DeletedAt.Valid {
+		deletedAt = f.DeletedAt.Time.Format("2006-01-02T15:04:05Z")
+	}
+
+	return models.FarmerResponse{
+		TempERPCustomerID: f.TempID,
+		TempERPVendorID:   f.TempVendorID,
+		ErpCustomerId:     f.CustomerID,
+		ErpVendorId:       f.VendorID,
+		FarmerId:          f.FarmerID,
+		CreatedAt:         f.RoleRegisteredAt(role).Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:         f.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		Status:            f.RoleStatus(role),
+		Message:           message,
+		DeletedAt:         deletedAt,
+	}
+}
+
+func farmerDetailResponse(farmer *models.FarmerDetails, role string) models.FarmerDetailResponse {
+	return models.FarmerDetailResponse{
 		FarmerID:           farmer.FarmerID,
 		Name:               farmer.FirstName + " " + farmer.LastName,
 		MobileNumber:       farmer.MobileNumber,
@@ -451,16 +986,15 @@ func GetVendorDetailHandler(c *fiber.Ctx) error {
 		ClubID:             farmer.ClubID,
 		ClubLeaderFarmerID: farmer.ClubLeaderFarmerID,
 		Message:            "Farmer detail fetched successfully",
-		EntityID:           farmer.TempID, // or permanent entity ID
+		EntityID:           farmer.RoleTempID(role), // or permanent entity ID
 		CustomerCode:       farmer.CustomerID,
 		VendorCode:         farmer.VendorID,
-		CreatedDate:        farmer.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		Status:             farmer.RoleStatus(role),
+		CreatedDate:        farmer.RoleRegisteredAt(role).Format("2006-01-02T15:04:05Z"),
 		UpdatedDate:        farmer.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 		// BankDetails: models.BankDetailsInfo{
 		// 	IBAN:  farmer.IBAN,   // ensure field exists
 		// 	SWIFT: farmer.SWIFT,  // ensure field exists
 		// },
 	}
-
-	return c.Status(fiber.StatusOK).JSON(response)
 }
@@ -0,0 +1,425 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/initializers"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/repository"
+	"github.com/shyamsundaar/karino-mock-server/services"
+)
+
+const farmerBody = `{"farmerId":"F1","firstName":"Ravi","lastName":"Kumar","farmer_kyc_type_id":2,"farmer_kyc_type":"PAN","farmer_kyc_id":"ABCDE1234F"}`
+
+// testRepositories returns a fresh memory and SQLite backend
+func testRepositories(t *testing.T) map[string]*repository.Repositories {
+	return map[string]*repository.Repositories{
+		"memory": initializers.ConnectRepositories(&initializers.Config{DBDriver: "memory"}),
+		"sqlite": initializers.ConnectRepositories(&initializers.Config{DBDriver: "sqlite", SQLitePath: filepath.Join(t.TempDir(), "farmers.db")}),
+	}
+}
+
+// rendezvousFarmers holds every farmer lookup until n of them were made, so
+// n concurrent requests all pass the duplicate checks before any of them
+// writes: the storage alone has to let exactly one through
+type rendezvousFarmers struct {
+	repository.FarmerRepository
+	arrived sync.WaitGroup
+}
+
+func withRendezvous(repos *repository.Repositories, n int) *repository.Repositories {
+	farmers := &rendezvousFarmers{FarmerRepository: repos.Farmers}
+	farmers.arrived.Add(n)
+
+	// Writes inside transactions go to the repositories the transaction
+	// hands out, which are not held
+	held := *repos
+	held.Farmers = farmers
+	return &held
+}
+
+func (r *rendezvousFarmers) FindByCoopAndFarmer(coopID, farmerID string) (*models.FarmerDetails, error) {
+	farmer, err := r.FarmerRepository.FindByCoopAndFarmer(coopID, farmerID)
+	r.arrived.Done()
+	r.arrived.Wait()
+	return farmer, err
+}
+
+// afterLookupFarmers runs hook after the first farmer lookup, the moment a
+// concurrent write lands between a handler's read and its write
+type afterLookupFarmers struct {
+	repository.FarmerRepository
+	hook func()
+	once sync.Once
+}
+
+func withAfterLookup(repos *repository.Repositories, hook func()) *repository.Repositories {
+	held := *repos
+	held.Farmers = &afterLookupFarmers{FarmerRepository: repos.Farmers, hook: hook}
+	return &held
+}
+
+func (r *afterLookupFarmers) FindByCoopAndFarmer(coopID, farmerID string) (*models.FarmerDetails, error) {
+	farmer, err := r.FarmerRepository.FindByCoopAndFarmer(coopID, farmerID)
+	r.once.Do(r.hook)
+	return farmer, err
+}
+
+// failingFarmers fails the lookup method named by fail, the way a dropped
+// database connection would
+type failingFarmers struct {
+	repository.FarmerRepository
+	fail string
+}
+
+var errConnectionLost = errors.New("connection lost")
+
+func withFailingLookup(repos *repository.Repositories, method string) *repository.Repositories {
+	failing := *repos
+	failing.Farmers = &failingFarmers{FarmerRepository: repos.Farmers, fail: method}
+	return &failing
+}
+
+func (r *failingFarmers) FindByCoopAndFarmer(coopID, farmerID string) (*models.FarmerDetails, error) {
+	if r.fail == "FindByCoopAndFarmer" {
+		return nil, errConnectionLost
+	}
+	return r.FarmerRepository.FindByCoopAndFarmer(coopID, farmerID)
+}
+
+func (r *failingFarmers) FindByKYC(kycID string) (*models.FarmerDetails, error) {
+	if r.fail == "FindByKYC" {
+		return nil, errConnectionLost
+	}
+	return r.FarmerRepository.FindByKYC(kycID)
+}
+
+func (r *failingFarmers) FindDeleted(coopID, farmerID string) (*models.FarmerDetails, error) {
+	if r.fail == "FindDeleted" {
+		return nil, errConnectionLost
+	}
+	return r.FarmerRepository.FindDeleted(coopID, farmerID)
+}
+
+// newTestApp serves the customer and vendor farmer routes over repos
+func newTestApp(t *testing.T, repos *repository.Repositories) *fiber.App {
+	kycTypes, err := services.NewKycCatalogue(services.DefaultKycTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	farmers := NewFarmerController(repos, nil, kycTypes)
+
+	app := fiber.New(fiber.Config{Immutable: true, ErrorHandler: ErrorHandler})
+	app.Post("/customers/:coopId/farmers", farmers.CreateCustomerDetailHandler)
+	app.Post("/customers/:coopId/farmers/bulk", farmers.BulkCreateCustomerDetailsHandler)
+	app.Get("/customers/:coopId/farmers/:farmerId", farmers.GetCustomerDetailHandler)
+	app.Patch("/customers/:coopId/farmers/:farmerId", farmers.UpdateCustomerDetailHandler)
+	app.Delete("/customers/:coopId/farmers/:farmerId", farmers.DeleteCustomerDetailHandler)
+	app.Post("/customers/:coopId/farmers/:farmerId/restore", farmers.RestoreCustomerDetailHandler)
+	app.Post("/vendors/:coopId/farmers", farmers.CreateVendorDetailHandler)
+	return app
+}
+
+// send makes one request and decodes the JSON response into out, when not nil
+func send(t *testing.T, app *fiber.App, method, path, body string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// errorCode sends the request and returns the status and error code
+func errorCode(t *testing.T, app *fiber.App, method, path, body string) (int, string) {
+	t.Helper()
+	var response models.ErrorFarmerResponse
+	status := send(t, app, method, path, body, &response)
+	return status, response.Code
+}
+
+// postConcurrently sends body to path from n goroutines at once and returns
+// the decoded responses by status
+func postConcurrently(t *testing.T, app *fiber.App, path, body string, n int) map[int][]models.CreateSuccessFarmerResponse {
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		start     = make(chan struct{})
+		responses = map[int][]models.CreateSuccessFarmerResponse{}
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			req := httptest.NewRequest(fiber.MethodPost, path, strings.NewReader(body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+
+			var response models.CreateSuccessFarmerResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			responses[resp.StatusCode] = append(responses[resp.StatusCode], response)
+			mu.Unlock()
+		}()
+	}
+	close(start)
+	wg.Wait()
+	return responses
+}
+
+func TestConcurrentCreatesYieldOneSuccess(t *testing.T) {
+	const n = 20
+	for name, repos := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			app := newTestApp(t, withRendezvous(repos, n))
+
+			responses := postConcurrently(t, app, "/customers/COOP001/farmers", farmerBody, n)
+			if len(responses[fiber.StatusCreated]) != 1 || len(responses[fiber.StatusConflict]) != n-1 {
+				t.Fatalf("got %d created and %d conflicts, want 1 and %d", len(responses[fiber.StatusCreated]), len(responses[fiber.StatusConflict]), n-1)
+			}
+
+			stored, total, err := repos.Farmers.List(repository.FarmerFilter{CoopID: "COOP001"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 1 || stored[0].TempID != responses[fiber.StatusCreated][0].Data.TempERPCustomerID {
+				t.Errorf("stored %d farmers, want the one answered with 201", total)
+			}
+		})
+	}
+}
+
+func TestConcurrentRoleAddsYieldOneSuccess(t *testing.T) {
+	const n = 20
+	for name, repos := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			if responses := postConcurrently(t, newTestApp(t, repos), "/customers/COOP001/farmers", farmerBody, 1); len(responses[fiber.StatusCreated]) != 1 {
+				t.Fatalf("registering the customer failed: %+v", responses)
+			}
+
+			app := newTestApp(t, withRendezvous(repos, n))
+			responses := postConcurrently(t, app, "/vendors/COOP001/farmers", farmerBody, n)
+			if len(responses[fiber.StatusCreated]) != 1 || len(responses[fiber.StatusConflict]) != n-1 {
+				t.Fatalf("got %d created and %d conflicts, want 1 and %d", len(responses[fiber.StatusCreated]), len(responses[fiber.StatusConflict]), n-1)
+			}
+
+			stored, err := repos.Farmers.FindByCoopAndFarmer("COOP001", "F1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := responses[fiber.StatusCreated][0].Data.TempERPVendorID; stored.TempVendorID != want {
+				t.Errorf("stored tempVendorId %s, want %s from the 201 response", stored.TempVendorID, want)
+			}
+			if stored.CustomerStatus != models.RegistrationPending {
+				t.Errorf("customer status %q, want it kept as %s", stored.CustomerStatus, models.RegistrationPending)
+			}
+		})
+	}
+}
+
+func TestNullBodyIsInvalid(t *testing.T) {
+	app := newTestApp(t, testRepositories(t)["memory"])
+
+	if status, code := errorCode(t, app, fiber.MethodPost, "/customers/COOP001/farmers", "null"); status != fiber.StatusBadRequest || code != models.ErrCodeInvalidBody {
+		t.Errorf("a null body got %d %s, want 400 %s", status, code, models.ErrCodeInvalidBody)
+	}
+}
+
+func TestUpdateKeepsConcurrentApproval(t *testing.T) {
+	for name, repos := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			if status := send(t, newTestApp(t, repos), fiber.MethodPost, "/customers/COOP001/farmers", farmerBody, nil); status != fiber.StatusCreated {
+				t.Fatalf("registering the customer got %d", status)
+			}
+
+			// The ERP approves the customer and the vendor role is added while
+			// the PATCH is between its read and its write
+			app := newTestApp(t, withAfterLookup(repos, func() {
+				farmer, err := repos.Farmers.FindByCoopAndFarmer("COOP001", "F1")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				now := time.Now().UTC()
+				farmer.CustomerID, farmer.CustomerStatus, farmer.CustIDUpdateAt, farmer.UpdatedAt = "CUS0000001", models.RegistrationApproved, &now, &now
+				if err := repos.Farmers.UpdateRole(farmer, models.RoleCustomer, models.RegistrationPending); err != nil {
+					t.Error(err)
+				}
+				farmer.AddRole(models.RoleVendor, now)
+				if err := repos.Farmers.UpdateRole(farmer, models.RoleVendor, ""); err != nil {
+					t.Error(err)
+				}
+			}))
+
+			var response models.CreateSuccessFarmerResponse
+			if status := send(t, app, fiber.MethodPatch, "/customers/COOP001/farmers/F1", `{"firstName":"Raghu"}`, &response); status != fiber.StatusOK {
+				t.Fatalf("PATCH got %d", status)
+			}
+			if !slices.Equal(response.Data.ChangedFields, []string{"firstName"}) {
+				t.Errorf("changed fields %v, want [firstName]", response.Data.ChangedFields)
+			}
+
+			stored, err := repos.Farmers.FindByCoopAndFarmer("COOP001", "F1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.FirstName != "Raghu" {
+				t.Errorf("first name %q, want the patched Raghu", stored.FirstName)
+			}
+			if stored.CustomerStatus != models.RegistrationApproved || stored.CustomerID != "CUS0000001" {
+				t.Errorf("customer %s %q, want the concurrent approval kept", stored.CustomerStatus, stored.CustomerID)
+			}
+			if !stored.HasRole(models.RoleVendor) {
+				t.Error("the vendor role added concurrently was lost")
+			}
+		})
+	}
+}
+
+func TestUpdateErrors(t *testing.T) {
+	app := newTestApp(t, testRepositories(t)["memory"])
+	send(t, app, fiber.MethodPost, "/customers/COOP001/farmers", farmerBody, nil)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"unknown farmer", "/customers/COOP001/farmers/F9", `{"firstName":"Raghu"}`, fiber.StatusNotFound, models.ErrCodeFarmerNotFound},
+		{"farmer ID changed", "/customers/COOP001/farmers/F1", `{"farmerId":"F2"}`, fiber.StatusUnprocessableEntity, models.ErrCodeFarmerIDImmutable},
+		{"invalid field", "/customers/COOP001/farmers/F1", `{"mobile_number":"12"}`, fiber.StatusUnprocessableEntity, models.ErrCodeValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, code := errorCode(t, app, fiber.MethodPatch, tt.path, tt.body); status != tt.status || code != tt.code {
+				t.Errorf("got %d %s, want %d %s", status, code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestDeleteAndRestore(t *testing.T) {
+	for name, repos := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			app := newTestApp(t, repos)
+			send(t, app, fiber.MethodPost, "/customers/COOP001/farmers", farmerBody, nil)
+			time.Sleep(10 * time.Millisecond)
+
+			var deleted models.CreateSuccessFarmerResponse
+			if status := send(t, app, fiber.MethodDelete, "/customers/COOP001/farmers/F1", "", &deleted); status != fiber.StatusOK {
+				t.Fatalf("DELETE got %d", status)
+			}
+			if deleted.Data.DeletedAt == "" {
+				t.Error("the response has no deletedAt")
+			}
+			stored, _, err := repos.Farmers.List(repository.FarmerFilter{WithDeleted: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stored) != 1 || !stored[0].DeletedAt.Valid || !stored[0].UpdatedAt.After(*stored[0].CreatedAt) {
+				t.Errorf("stored %+v, want it deleted with updatedAt bumped", stored)
+			}
+			if status, code := errorCode(t, app, fiber.MethodGet, "/customers/COOP001/farmers/F1", ""); status != fiber.StatusNotFound || code != models.ErrCodeFarmerNotFound {
+				t.Errorf("GET after delete got %d %s, want 404 %s", status, code, models.ErrCodeFarmerNotFound)
+			}
+			if status, code := errorCode(t, app, fiber.MethodDelete, "/customers/COOP001/farmers/F1", ""); status != fiber.StatusNotFound || code != models.ErrCodeFarmerNotFound {
+				t.Errorf("second DELETE got %d %s, want 404 %s", status, code, models.ErrCodeFarmerNotFound)
+			}
+
+			if status := send(t, app, fiber.MethodPost, "/customers/COOP001/farmers/F1/restore", "", nil); status != fiber.StatusOK {
+				t.Fatalf("restore got %d", status)
+			}
+			if status := send(t, app, fiber.MethodGet, "/customers/COOP001/farmers/F1", "", nil); status != fiber.StatusOK {
+				t.Errorf("GET after restore got %d", status)
+			}
+			if status, code := errorCode(t, app, fiber.MethodPost, "/customers/COOP001/farmers/F1/restore", ""); status != fiber.StatusNotFound || code != models.ErrCodeFarmerNotFound {
+				t.Errorf("second restore got %d %s, want 404 %s", status, code, models.ErrCodeFarmerNotFound)
+			}
+		})
+	}
+}
+
+// A failed lookup is a storage error, not a missing farmer
+func TestLookupFailuresAreStorageErrors(t *testing.T) {
+	repos := testRepositories(t)["memory"]
+	app := newTestApp(t, repos)
+	send(t, app, fiber.MethodPost, "/customers/COOP001/farmers", farmerBody, nil)
+	send(t, app, fiber.MethodPost, "/customers/COOP001/farmers", secondFarmerBody, nil)
+	send(t, app, fiber.MethodDelete, "/customers/COOP001/farmers/F2", "", nil)
+
+	tests := []struct {
+		failing      string
+		method, path string
+		body         string
+	}{
+		{"FindByCoopAndFarmer", fiber.MethodGet, "/customers/COOP001/farmers/F1", ""},
+		{"FindByCoopAndFarmer", fiber.MethodPatch, "/customers/COOP001/farmers/F1", `{"firstName":"Raghu"}`},
+		{"FindByKYC", fiber.MethodPatch, "/customers/COOP001/farmers/F1", `{"firstName":"Raghu"}`},
+		{"FindByCoopAndFarmer", fiber.MethodDelete, "/customers/COOP001/farmers/F1", ""},
+		{"FindDeleted", fiber.MethodPost, "/customers/COOP001/farmers/F2/restore", ""},
+		{"FindByCoopAndFarmer", fiber.MethodPost, "/customers/COOP001/farmers/F2/restore", ""},
+		{"FindByKYC", fiber.MethodPost, "/customers/COOP001/farmers/F2/restore", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path+" "+tt.failing, func(t *testing.T) {
+			app := newTestApp(t, withFailingLookup(repos, tt.failing))
+			if status, code := errorCode(t, app, tt.method, tt.path, tt.body); status != fiber.StatusBadGateway || code != models.ErrCodeStorage {
+				t.Errorf("got %d %s, want 502 %s", status, code, models.ErrCodeStorage)
+			}
+		})
+	}
+}
+
+// Adding the second role only writes the role, so a payload with other
+// details is rejected instead of being dropped
+func TestSecondRoleDetails(t *testing.T) {
+	repos := testRepositories(t)["memory"]
+	app := newTestApp(t, repos)
+	send(t, app, fiber.MethodPost, "/customers/COOP001/farmers", farmerBody, nil)
+
+	renamed := strings.Replace(farmerBody, `"Ravi"`, `"Raghu"`, 1)
+	if status, code := errorCode(t, app, fiber.MethodPost, "/vendors/COOP001/farmers", renamed); status != fiber.StatusConflict || code != models.ErrCodeDetailsMismatch {
+		t.Errorf("got %d %s, want 409 %s", status, code, models.ErrCodeDetailsMismatch)
+	}
+	stored, err := repos.Farmers.FindByCoopAndFarmer("COOP001", "F1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.FirstName != "Ravi" || stored.HasRole(models.RoleVendor) {
+		t.Errorf("stored %s with vendor status %q, want Ravi without the role", stored.FirstName, stored.VendorStatus)
+	}
+
+	if status := send(t, app, fiber.MethodPost, "/vendors/COOP001/farmers", farmerBody, nil); status != fiber.StatusCreated {
+		t.Errorf("same details got %d, want 201", status)
+	}
+}
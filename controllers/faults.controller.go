@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/middleware"
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// FaultController serves the admin endpoints that manage fault rules
+type FaultController struct {
+	faults *middleware.FaultInjector
+}
+
+func NewFaultController(faults *middleware.FaultInjector) *FaultController {
+	return &FaultController{faults: faults}
+}
+
+// FindFaultsHandler handles GET /__admin/faults
+// @Summary      List fault rules
+// @Tags         Admin
+// @Produce      json
+// @Success      200  {object}  models.ListFaultRulesResponse
+// @Router       /__admin/faults [get]
+func (h *FaultController) FindFaultsHandler(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(models.ListFaultRulesResponse{Success: true, Data: h.faults.Rules()})
+}
+
+// SaveFaultHandler handles POST /__admin/faults
+// @Summary      Add or replace a fault rule
+// @Description  A rule with the ID of an existing one replaces it; a rule without an ID gets one. Rules are evaluated in order and the first hit fails the request.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        fault  body      models.FaultRule            true  "Fault rule"
+// @Success      201    {object}  models.FaultRuleResponse
+// @Failure      400    {object}  models.ErrorFarmerResponse  "INVALID_BODY"
+// @Failure      422    {object}  models.ErrorFarmerResponse  "VALIDATION_ERROR"
+// @Router       /__admin/faults [post]
+func (h *FaultController) SaveFaultHandler(c *fiber.Ctx) error {
+	var payload models.FaultRule
+	if err := c.BodyParser(&payload); err != nil {
+		return SendErrorResponse(c, invalidBodyError(err), "")
+	}
+	if fields := models.ValidateStruct(&payload); len(fields) > 0 {
+		return SendErrorResponse(c, validationError(fields), "")
+	}
+
+	rule := h.faults.Add(payload)
+	return c.Status(fiber.StatusCreated).JSON(models.FaultRuleResponse{Success: true, Data: rule})
+}
+
+// DeleteFaultHandler handles DELETE /__admin/faults/:id
+// @Summary      Remove a fault rule
+// @Tags         Admin
+// @Produce      json
+// @Param        id   path      string  true  "Fault rule ID"
+// @Success      204
+// @Failure      404  {object}  models.ErrorFarmerResponse  "FAULT_NOT_FOUND"
+// @Router       /__admin/faults/{id} [delete]
+func (h *FaultController) DeleteFaultHandler(c *fiber.Ctx) error {
+	if !h.faults.Remove(c.Params("id")) {
+		return SendErrorResponse(c, newAPIError(fiber.StatusNotFound, models.ErrCodeFaultNotFound, "The fault rule "+c.Params("id")+" does not exist."), "")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ResetFaultsHandler handles DELETE /__admin/faults
+// @Summary      Remove every fault rule
+// @Tags         Admin
+// @Success      204
+// @Router       /__admin/faults [delete]
+func (h *FaultController) ResetFaultsHandler(c *fiber.Ctx) error {
+	h.faults.Reset()
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/services"
+)
+
+// GeographyController serves lookups of the geography master data for
+// address pickers
+type GeographyController struct {
+	geography *services.Geography
+}
+
+func NewGeographyController(geography *services.Geography) *GeographyController {
+	return &GeographyController{geography: geography}
+}
+
+// ListGeographyHandler handles GET /spic_to_erp/geography/:level
+// @Summary      List geography nodes
+// @Description  List the nodes of a level: region, region-part, settlement, settlement-part, custom-1 or custom-2
+// @Tags         Geography
+// @Produce      json
+// @Param        level     path      string  true   "Geography level"
+// @Param        parentId  query     string  false  "Only children of this node of the level above"
+// @Param        zipCode   query     string  false  "Only nodes with this zip code"
+// @Success      200       {object}  models.GeographyListResponse
+// @Failure      404       {object}  models.ErrorFarmerResponse  "GEOGRAPHY_NOT_FOUND"
+// @Router       /spic_to_erp/geography/{level} [get]
+func (h *GeographyController) ListGeographyHandler(c *fiber.Ctx) error {
+	level := c.Params("level")
+	if _, ok := models.GeographyParentLevel[level]; !ok {
+		return SendErrorResponse(c, newAPIError(fiber.StatusNotFound, models.ErrCodeGeographyNotFound, "Unknown geography level "+level+"."), "")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.GeographyListResponse{
+		Success: true,
+		Data:    h.geography.List(level, c.Query("parentId"), c.Query("zipCode")),
+	})
+}
+
+// GetGeographyHandler handles GET /spic_to_erp/geography/:level/:id
+// @Summary      Get a geography node
+// @Description  Get a node with its direct children
+// @Tags         Geography
+// @Produce      json
+// @Param        level  path      string  true  "Geography level"
+// @Param        id     path      string  true  "Node ID"
+// @Success      200    {object}  models.GeographyNodeResponse
+// @Failure      404    {object}  models.ErrorFarmerResponse  "GEOGRAPHY_NOT_FOUND"
+// @Router       /spic_to_erp/geography/{level}/{id} [get]
+func (h *GeographyController) GetGeographyHandler(c *fiber.Ctx) error {
+	level := c.Params("level")
+	id := c.Params("id")
+
+	node, ok := h.geography.Find(level, id)
+	if !ok {
+		return SendErrorResponse(c, newAPIError(fiber.StatusNotFound, models.ErrCodeGeographyNotFound, "No "+level+" with ID "+id+"."), "")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.GeographyNodeResponse{
+		Success:  true,
+		Data:     node,
+		Children: h.geography.Children(level, id),
+	})
+}
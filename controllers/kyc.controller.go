@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/services"
+)
+
+// KycController serves the KYC type catalogue
+type KycController struct {
+	kycTypes *services.KycCatalogue
+}
+
+func NewKycController(kycTypes *services.KycCatalogue) *KycController {
+	return &KycController{kycTypes: kycTypes}
+}
+
+// FindKycTypesHandler handles GET /spic_to_erp/kyc-types
+// @Summary      List KYC types
+// @Description  The KYC types accepted in farmer_kyc_type_id and farmer_kyc_type, with the format their KYC IDs must match
+// @Tags         KYC
+// @Produce      json
+// @Success      200  {object}  models.ListKycTypesResponse
+// @Router       /spic_to_erp/kyc-types [get]
+func (h *KycController) FindKycTypesHandler(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(models.ListKycTypesResponse{Success: true, Data: h.kycTypes.List()})
+}
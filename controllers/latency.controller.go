@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/middleware"
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// LatencyController serves the admin endpoints of the latency simulation
+type LatencyController struct {
+	latency *middleware.Latency
+}
+
+func NewLatencyController(latency *middleware.Latency) *LatencyController {
+	return &LatencyController{latency: latency}
+}
+
+// GetLatencyHandler handles GET /__admin/latency
+// @Summary      Show the latency simulation settings
+// @Tags         Admin
+// @Produce      json
+// @Success      200  {object}  models.LatencySettingsResponse
+// @Router       /__admin/latency [get]
+func (h *LatencyController) GetLatencyHandler(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(models.LatencySettingsResponse{Success: true, Data: h.latency.Settings()})
+}
+
+// UpdateLatencyHandler handles PUT /__admin/latency
+// @Summary      Turn the latency simulation on or off, or replace its rules
+// @Description  Only the fields present in the body change, so {"enabled": false} keeps the rules. The first rule matching a request sets its delay.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        settings  body      models.LatencySettings      true  "Settings to change"
+// @Success      200       {object}  models.LatencySettingsResponse
+// @Failure      400       {object}  models.ErrorFarmerResponse  "INVALID_BODY"
+// @Failure      422       {object}  models.ErrorFarmerResponse  "VALIDATION_ERROR"
+// @Router       /__admin/latency [put]
+func (h *LatencyController) UpdateLatencyHandler(c *fiber.Ctx) error {
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &present); err != nil {
+		return SendErrorResponse(c, invalidBodyError(err), "")
+	}
+
+	settings := h.latency.Settings()
+	if err := json.Unmarshal(c.Body(), &settings); err != nil {
+		return SendErrorResponse(c, invalidBodyError(err), "")
+	}
+	if _, ok := present["rules"]; ok {
+		// Unmarshal merges into the existing slice; start from the body alone
+		settings.Rules = nil
+		if err := json.Unmarshal(present["rules"], &settings.Rules); err != nil {
+			return SendErrorResponse(c, invalidBodyError(err), "")
+		}
+	}
+	if fields := models.ValidateStruct(&settings); len(fields) > 0 {
+		return SendErrorResponse(c, validationError(fields), "")
+	}
+
+	h.latency.Set(settings)
+	return c.Status(fiber.StatusOK).JSON(models.LatencySettingsResponse{Success: true, Data: settings})
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/middleware"
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// newLatencyTestApp serves the latency admin endpoints and answers every
+// farmer route with 200 behind the latency middleware
+func newLatencyTestApp() *fiber.App {
+	latency := middleware.NewLatency()
+	settings := NewLatencyController(latency)
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(latency.Handler)
+	app.Get("/__admin/latency", settings.GetLatencyHandler)
+	app.Put("/__admin/latency", settings.UpdateLatencyHandler)
+	app.All("/spic_to_erp/*", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+// timed sends a GET to path and returns how long the response took
+func timed(t *testing.T, app *fiber.App, path string) time.Duration {
+	t.Helper()
+	start := time.Now()
+	if status := send(t, app, fiber.MethodGet, path, "", nil); status != fiber.StatusOK {
+		t.Fatalf("GET %s got %d", path, status)
+	}
+	return time.Since(start)
+}
+
+func TestLatencySettings(t *testing.T) {
+	app := newLatencyTestApp()
+	const delay = 100 * time.Millisecond
+
+	rules := `{"enabled":true,"rules":[{"route":"/spic_to_erp/customers/*","distribution":"fixed","delayMs":100}]}`
+	var updated models.LatencySettingsResponse
+	if status := send(t, app, fiber.MethodPut, "/__admin/latency", rules, &updated); status != fiber.StatusOK || len(updated.Data.Rules) != 1 {
+		t.Fatalf("PUT got %d with %d rules, want 200 with 1", status, len(updated.Data.Rules))
+	}
+	if took := timed(t, app, "/spic_to_erp/customers/COOP001/farmers"); took < delay {
+		t.Errorf("matching route took %s, want at least %s", took, delay)
+	}
+	if took := timed(t, app, "/spic_to_erp/vendors/COOP001/farmers"); took >= delay {
+		t.Errorf("other route took %s, want less than %s", took, delay)
+	}
+	if took := timed(t, app, "/__admin/latency"); took >= delay {
+		t.Errorf("admin API took %s, want less than %s", took, delay)
+	}
+
+	// Turning the simulation off keeps the rules
+	var disabled models.LatencySettingsResponse
+	send(t, app, fiber.MethodPut, "/__admin/latency", `{"enabled":false}`, &disabled)
+	if disabled.Data.Enabled || len(disabled.Data.Rules) != 1 {
+		t.Errorf("got enabled %t with %d rules, want disabled with 1", disabled.Data.Enabled, len(disabled.Data.Rules))
+	}
+	if took := timed(t, app, "/spic_to_erp/customers/COOP001/farmers"); took >= delay {
+		t.Errorf("disabled simulation took %s, want less than %s", took, delay)
+	}
+}
+
+func TestLatencySettingsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   string
+	}{
+		{"not JSON", `{"enabled":`, fiber.StatusBadRequest, models.ErrCodeInvalidBody},
+		{"unknown distribution", `{"rules":[{"distribution":"poisson"}]}`, fiber.StatusUnprocessableEntity, models.ErrCodeValidation},
+		{"inverted range", `{"rules":[{"distribution":"uniform","minMs":500,"maxMs":100}]}`, fiber.StatusUnprocessableEntity, models.ErrCodeValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newLatencyTestApp()
+			if status, code := errorCode(t, app, fiber.MethodPut, "/__admin/latency", tt.body); status != tt.status || code != tt.code {
+				t.Errorf("got %d %s, want %d %s", status, code, tt.status, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/middleware"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"gopkg.in/yaml.v3"
+)
+
+// ScenarioController serves the admin endpoints of the scenario engine
+type ScenarioController struct {
+	scenarios *middleware.Scenarios
+}
+
+func NewScenarioController(scenarios *middleware.Scenarios) *ScenarioController {
+	return &ScenarioController{scenarios: scenarios}
+}
+
+// FindScenariosHandler handles GET /__admin/scenarios
+// @Summary      List scenarios and how many of their responses were served
+// @Tags         Admin
+// @Produce      json
+// @Success      200  {object}  models.ListScenariosResponse
+// @Router       /__admin/scenarios [get]
+func (h *ScenarioController) FindScenariosHandler(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(models.ListScenariosResponse{Success: true, Data: h.scenarios.List()})
+}
+
+// SaveScenarioHandler handles POST /__admin/scenarios
+// @Summary      Add or replace a scenario
+// @Description  The body is a scenario in JSON or YAML. A scenario with the ID of an existing one replaces it and starts its sequence over. Scenarios are matched in order.
+// @Tags         Admin
+// @Accept       json
+// @Accept       x-yaml
+// @Produce      json
+// @Param        scenario  body      models.Scenario             true  "Scenario"
+// @Success      201       {object}  models.ScenarioResponseBody
+// @Failure      400       {object}  models.ErrorFarmerResponse  "INVALID_BODY"
+// @Failure      422       {object}  models.ErrorFarmerResponse  "VALIDATION_ERROR"
+// @Router       /__admin/scenarios [post]
+func (h *ScenarioController) SaveScenarioHandler(c *fiber.Ctx) error {
+	// YAML is a superset of JSON, so one decoder reads both
+	var payload models.Scenario
+	if err := yaml.Unmarshal(c.Body(), &payload); err != nil {
+		return SendErrorResponse(c, invalidBodyError(err), "")
+	}
+	if fields := models.ValidateStruct(&payload); len(fields) > 0 {
+		return SendErrorResponse(c, validationError(fields), "")
+	}
+
+	state := h.scenarios.Add(payload)
+	return c.Status(fiber.StatusCreated).JSON(models.ScenarioResponseBody{Success: true, Data: state})
+}
+
+// ClearScenariosHandler handles DELETE /__admin/scenarios
+// @Summary      Remove every scenario
+// @Description  Loaded scenarios come back with POST /__admin/scenarios/reset
+// @Tags         Admin
+// @Success      204
+// @Router       /__admin/scenarios [delete]
+func (h *ScenarioController) ClearScenariosHandler(c *fiber.Ctx) error {
+	h.scenarios.Clear()
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ResetScenariosHandler handles POST /__admin/scenarios/reset
+// @Summary      Go back to the scenarios loaded at startup
+// @Description  Drops scenarios added at runtime and starts every sequence over. Call it between test cases.
+// @Tags         Admin
+// @Produce      json
+// @Success      200  {object}  models.ListScenariosResponse
+// @Router       /__admin/scenarios/reset [post]
+func (h *ScenarioController) ResetScenariosHandler(c *fiber.Ctx) error {
+	h.scenarios.Reset()
+	return c.Status(fiber.StatusOK).JSON(models.ListScenariosResponse{Success: true, Data: h.scenarios.List()})
+}
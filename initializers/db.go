@@ -5,20 +5,37 @@ import (
 	"log"
 	"os"
 
+	"github.com/google/uuid"
 	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/query"
+	"github.com/shyamsundaar/karino-mock-server/repository"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 
+// ConnectRepositories opens the storage backend selected by DB_DRIVER
+func ConnectRepositories(config *Config) *repository.Repositories {
+	switch config.DBDriver {
+	case "memory", "":
+		log.Println("🧪 Using the in-memory storage backend, data is lost on restart")
+		return repository.NewMemoryRepositories()
+	case "mysql", "sqlite":
+		ConnectDB(config)
+		return repository.NewGormRepositories(DB)
+	default:
+		log.Fatalf("Unsupported DB_DRIVER %q, expected mysql, sqlite or memory", config.DBDriver)
+		return nil
+	}
+}
+
 func ConnectDB(config *Config) {
 	var err error
-	// dsn := fmt.Sprintf("user:pass@tcp(127.0.0.1:3306)/dbname?charset=utf8mb4&parseTime=True&loc=Local")
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", config.DBUserName, config.DBUserPassword, config.DBHost, config.DBPort, config.DBName)
 
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(dialector(config), &gorm.Config{})
 	if err != nil {
 		log.Fatal("Failed to connect to the Database! \n", err.Error())
 		os.Exit(1)
@@ -27,7 +44,65 @@ func ConnectDB(config *Config) {
 	DB.Logger = logger.Default.LogMode(logger.Info)
 
 	log.Println("Running Migrations")
-	DB.AutoMigrate(&models.FarmerDetails{})
+	DB.AutoMigrate(&models.FarmerDetails{}, &models.Cooperative{}, &models.Club{})
+	backfillUniqueKeys()
+	backfillCustomerStatus()
 
 	log.Println("🚀 Connected Successfully to the Database")
 }
+
+func dialector(config *Config) gorm.Dialector {
+	if config.DBDriver == "sqlite" {
+		log.Println("Using SQLite database file", config.SQLitePath)
+		// Concurrent writers wait for the lock instead of failing with
+		// "database is locked"; immediate transactions take it up front, so
+		// two transactions that read before writing cannot deadlock
+		return sqlite.Open(config.SQLitePath + "?_busy_timeout=5000&_txlock=immediate")
+	}
+
+	// dsn := fmt.Sprintf("user:pass@tcp(127.0.0.1:3306)/dbname?charset=utf8mb4&parseTime=True&loc=Local")
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", config.DBUserName, config.DBUserPassword, config.DBHost, config.DBPort, config.DBName)
+	return mysql.Open(dsn)
+}
+
+// backfillUniqueKeys fills the unique keys of farmers stored before the keys
+// existed. Rows that already duplicate another farmer keep NULL keys and are
+// logged, since only one of them can hold the key.
+func backfillUniqueKeys() {
+	f := query.Use(DB).FarmerDetails
+	farmers, err := f.Where(f.FarmerKey.IsNull()).Find()
+	if err != nil {
+		log.Println("Failed to look up farmers without unique keys:", err)
+		return
+	}
+	for _, farmer := range farmers {
+		if err := DB.Model(farmer).Select("farmer_key", "kyc_key").Updates(farmer).Error; err != nil {
+			log.Printf("Farmer %s of cooperative %s duplicates another farmer and was not indexed: %v", farmer.FarmerID, farmer.CoopID, err)
+		}
+	}
+}
+
+// backfillCustomerStatus registers farmers stored before roles were tracked
+// as customers, the only role there was: approved if the ERP code was already
+// issued, pending otherwise. Their registration time stays unset, so the
+// promotion delay counts from CreatedAt.
+func backfillCustomerStatus() {
+	f := query.Use(DB).FarmerDetails
+	farmers, err := f.Unscoped().Where(f.CustomerStatus.Eq(""), f.VendorStatus.Eq("")).Find()
+	if err != nil {
+		log.Println("Failed to look up farmers without a registration status:", err)
+		return
+	}
+	for _, farmer := range farmers {
+		farmer.CustomerStatus = models.RegistrationPending
+		if farmer.CustomerID != "" {
+			farmer.CustomerStatus = models.RegistrationApproved
+		}
+		if farmer.TempID == "" {
+			farmer.TempID = uuid.New().String()
+		}
+		if err := DB.Unscoped().Model(farmer).Select("customer_status", "temp_id").Updates(farmer).Error; err != nil {
+			log.Printf("Failed to set the customer status of farmer %s of cooperative %s: %v", farmer.FarmerID, farmer.CoopID, err)
+		}
+	}
+}
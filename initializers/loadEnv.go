@@ -1,17 +1,60 @@
 package initializers
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
+	DBDriver       string `mapstructure:"DB_DRIVER"`
 	DBHost         string `mapstructure:"MYSQL_HOST"`
 	DBUserName     string `mapstructure:"MYSQL_USER"`
 	DBUserPassword string `mapstructure:"MYSQL_PASSWORD"`
 	DBName         string `mapstructure:"MYSQL_DATABASE"`
 	DBPort         string `mapstructure:"MYSQL_PORT"`
+	SQLitePath     string `mapstructure:"SQLITE_PATH"`
 
 	ClientOrigin string `mapstructure:"CLIENT_ORIGIN"`
+
+	// Token required by the /__admin routes; empty disables them
+	AdminToken string `mapstructure:"ADMIN_TOKEN"`
+
+	// JSON file loaded into the cooperative registry at startup
+	CooperativesSeedFile string `mapstructure:"COOPERATIVES_SEED_FILE"`
+	// Geography master data (.csv or .json) that farmer addresses must match
+	GeographyFile string `mapstructure:"GEOGRAPHY_FILE"`
+	// JSON KYC type catalogue; the built-in catalogue is used when empty
+	KycTypesFile string `mapstructure:"KYC_TYPES_FILE"`
+
+	// How long a response is replayed for a repeated Idempotency-Key
+	IdempotencyTTL time.Duration `mapstructure:"IDEMPOTENCY_TTL"`
+
+	// JSON array of fault rules applied from startup
+	FaultRulesFile string `mapstructure:"FAULT_RULES_FILE"`
+	// How long a timeout fault holds a request when its rule sets no delay
+	FaultTimeout time.Duration `mapstructure:"FAULT_TIMEOUT"`
+
+	// JSON array of latency rules, applied when LatencyEnabled is set
+	LatencyRulesFile string `mapstructure:"LATENCY_RULES_FILE"`
+	LatencyEnabled   bool   `mapstructure:"LATENCY_ENABLED"`
+
+	// Scenario file (.yaml, .yml or .json) or directory of them
+	ScenariosPath string `mapstructure:"SCENARIOS_PATH"`
+
+	// mock (default), proxy to record the ERP at ErpUpstreamURL into
+	// ErpRecordingsFile, or replay to serve those recordings
+	ErpMode            string        `mapstructure:"ERP_MODE"`
+	ErpUpstreamURL     string        `mapstructure:"ERP_UPSTREAM_URL"`
+	ErpUpstreamTimeout time.Duration `mapstructure:"ERP_UPSTREAM_TIMEOUT"`
+	ErpRecordingsFile  string        `mapstructure:"ERP_RECORDINGS_FILE"`
+
+	// Simulated ERP approval of pending customer/vendor registrations
+	ErpApprovalDelay      time.Duration `mapstructure:"ERP_APPROVAL_DELAY"`
+	ErpApprovalInterval   time.Duration `mapstructure:"ERP_APPROVAL_INTERVAL"`
+	ErpApprovalRule       string        `mapstructure:"ERP_APPROVAL_RULE"`
+	ErpCustomerCodePrefix string        `mapstructure:"ERP_CUSTOMER_CODE_PREFIX"`
+	ErpVendorCodePrefix   string        `mapstructure:"ERP_VENDOR_CODE_PREFIX"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -19,6 +62,22 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigType("env")
 	viper.SetConfigName("app")
 
+	viper.SetDefault("DB_DRIVER", "memory")
+	viper.SetDefault("SQLITE_PATH", "karino-mock.db")
+	viper.SetDefault("COOPERATIVES_SEED_FILE", "seed/cooperatives.json")
+	viper.SetDefault("GEOGRAPHY_FILE", "seed/geography.csv")
+	viper.SetDefault("IDEMPOTENCY_TTL", "24h")
+	viper.SetDefault("FAULT_TIMEOUT", "30s")
+	viper.SetDefault("LATENCY_RULES_FILE", "seed/latency.json")
+	viper.SetDefault("ERP_MODE", "mock")
+	viper.SetDefault("ERP_UPSTREAM_TIMEOUT", "30s")
+	viper.SetDefault("ERP_RECORDINGS_FILE", "recordings/erp.jsonl")
+	viper.SetDefault("ERP_APPROVAL_DELAY", "30s")
+	viper.SetDefault("ERP_APPROVAL_INTERVAL", "5s")
+	viper.SetDefault("ERP_APPROVAL_RULE", "any")
+	viper.SetDefault("ERP_CUSTOMER_CODE_PREFIX", "CUS")
+	viper.SetDefault("ERP_VENDOR_CODE_PREFIX", "VEN")
+
 	viper.AutomaticEnv()
 
 	err = viper.ReadInConfig()
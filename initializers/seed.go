@@ -0,0 +1,57 @@
+package initializers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/repository"
+)
+
+// SeedCooperatives adds the cooperatives listed in the JSON file at path to
+// the registry. Cooperatives already stored are left alone, so changes made
+// through the admin API survive restarts with persistent storage. An empty
+// path or missing file seeds nothing.
+func SeedCooperatives(repo repository.CooperativeRepository, path string) {
+	if path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("Cooperatives seed file %s not found, the registry starts empty", path)
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to read the cooperatives seed file %s: %v", path, err)
+	}
+
+	var entries []models.CooperativeSchema
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.Fatalf("Failed to parse the cooperatives seed file %s: %v", path, err)
+	}
+
+	now := time.Now().UTC()
+	seeded := 0
+	for _, entry := range entries {
+		if errs := models.ValidateStruct(&entry); len(errs) > 0 {
+			log.Fatalf("Invalid cooperative %q in %s: %s", entry.ID, path, errs[0].Message)
+		}
+		_, err := repo.Find(entry.ID)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, repository.ErrCooperativeNotFound) {
+			log.Fatalf("Failed to look up cooperative %q: %v", entry.ID, err)
+		}
+		coop := models.Cooperative{ID: entry.ID, Name: entry.Name, Active: entry.Active == nil || *entry.Active, CreatedAt: &now, UpdatedAt: &now}
+		if err := repo.Save(&coop); err != nil {
+			log.Fatalf("Failed to seed cooperative %q: %v", entry.ID, err)
+		}
+		seeded++
+	}
+	log.Printf("Seeded %d of the %d cooperatives in %s", seeded, len(entries), path)
+}
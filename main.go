@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"os"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/swagger" // Note: v2 uses this path usually
 	"github.com/shyamsundaar/karino-mock-server/controllers"
 	"github.com/shyamsundaar/karino-mock-server/initializers"
+	"github.com/shyamsundaar/karino-mock-server/middleware"
+	"github.com/shyamsundaar/karino-mock-server/repository"
+	"github.com/shyamsundaar/karino-mock-server/services"
 
 	// IMPORTANT: Replace this with your actual docs path generated by 'swag init'
 	_ "github.com/shyamsundaar/karino-mock-server/docs"
@@ -25,16 +32,36 @@ import (
 // @host localhost:8000
 // @BasePath /
 func main() {
-	app := fiber.New()
-	micro := fiber.New()
+	// Immutable: handler values such as c.Params() outlive the request when
+	// they are kept by the in-memory storage backend
+	app := fiber.New(fiber.Config{Immutable: true, ErrorHandler: controllers.ErrorHandler})
+	micro := fiber.New(fiber.Config{Immutable: true, ErrorHandler: controllers.ErrorHandler})
+
+	farmers := controllers.NewFarmerController(repos, geography, kycTypes)
+	geographyLookup := controllers.NewGeographyController(geography)
+	kycLookup := controllers.NewKycController(kycTypes)
+	idempotency := middleware.NewIdempotency(config.IdempotencyTTL)
+	cooperatives := controllers.NewCooperativeController(repos.Cooperatives)
+	faultRules := controllers.NewFaultController(faults)
+	latencySettings := controllers.NewLatencyController(latency)
+	scenarioScripts := controllers.NewScenarioController(scenarios)
+	admin := controllers.NewAdminController(repos, farmers, idempotency, scenarios)
 
 	// Middleware
 	app.Use(logger.New())
+	// A panicking handler answers 500 INTERNAL_ERROR instead of stopping the server
+	app.Use(recover.New(recover.Config{EnableStackTrace: true}))
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, Idempotency-Key, " + middleware.FaultHeader + ", " + middleware.AdminTokenHeader,
 		AllowMethods: "GET,POST,PUT,PATCH,DELETE,OPTIONS",
 	}))
+	app.Use(latency.Handler)
+	app.Use(faults.Handler)
+	app.Use(scenarios.Handler)
+	if erpTraffic != nil {
+		app.Use(erpTraffic)
+	}
 
 	// Swagger Route (Accessible at http://localhost:8000/swagger/index.html)
 	app.Get("/swagger/*", swagger.HandlerDefault)
@@ -44,26 +71,70 @@ func main() {
 
 	// --- Details Routes ---
 	micro.Route("/spic_to_erp", func(router fiber.Router) {
+		// Every farmer route answers INVALID_COOP for an unknown or inactive coopId
+		router.Use("/customers/:coopId", cooperatives.RequireActiveCooperative)
+		router.Use("/vendors/:coopId", cooperatives.RequireActiveCooperative)
+
+		router.Get("/geography/:level", geographyLookup.ListGeographyHandler)
+		router.Get("/geography/:level/:id", geographyLookup.GetGeographyHandler)
+		router.Get("/kyc-types", kycLookup.FindKycTypesHandler)
+
 		router.Route("/customers", func(router fiber.Router) {
-			router.Post("/:coopId/farmers", controllers.CreateCustomerDetailHandler)
-			// router.Get("/:coopId/cust_farmerslist", controllers.FindCustomerDetailsHandler) // <--- ADD THIS
-			// router.Post("/:coopId/vendor_farmers", controllers.CreateVendorDetailHandler)   // <--- ADD THIS
-			// router.Get("/:coopId/vendor_farmerslist", controllers.FindVendorDetailsHandler) // <--- ADD THIS
-			// router.Get("/:coopId/vendor_farmerslist", controllers.GetCustomerDetailHandler) // <--- ADD THIS
-			// router.Get("/:coopId/vendor_farmerslist", controllers.GetVendorDetailHandler)   // <--- ADD THIS
+			router.Post("/:coopId/farmers", idempotency.Handler, farmers.CreateCustomerDetailHandler)
+			router.Post("/:coopId/farmers/bulk", farmers.BulkCreateCustomerDetailsHandler)
+			router.Get("/:coopId/farmers", farmers.FindCustomerDetailsHandler)
+			router.Get("/:coopId/farmers/:farmerId", farmers.GetCustomerDetailHandler)
+			router.Patch("/:coopId/farmers/:farmerId", farmers.UpdateCustomerDetailHandler)
+			router.Delete("/:coopId/farmers/:farmerId", farmers.DeleteCustomerDetailHandler)
+			router.Post("/:coopId/farmers/:farmerId/restore", farmers.RestoreCustomerDetailHandler)
+			router.Get("/:coopId/clubs/:clubId/farmers", farmers.FindCustomerClubFarmersHandler)
 		})
 
 		router.Route("/vendors", func(router fiber.Router) {
-			router.Post("/:coopId/farmers", controllers.CreateVendorDetailHandler)
-
+			router.Post("/:coopId/farmers", idempotency.Handler, farmers.CreateVendorDetailHandler)
+			router.Post("/:coopId/farmers/bulk", farmers.BulkCreateVendorDetailsHandler)
+			router.Get("/:coopId/farmers", farmers.FindVendorDetailsHandler)
+			router.Get("/:coopId/farmers/:farmerId", farmers.GetVendorDetailHandler)
+			router.Patch("/:coopId/farmers/:farmerId", farmers.UpdateVendorDetailHandler)
+			router.Delete("/:coopId/farmers/:farmerId", farmers.DeleteVendorDetailHandler)
+			router.Post("/:coopId/farmers/:farmerId/restore", farmers.RestoreVendorDetailHandler)
+			router.Get("/:coopId/clubs/:clubId/farmers", farmers.FindVendorClubFarmersHandler)
 		})
 	})
 
-	// micro.Route("/details/:detailId", func(router fiber.Router) {
-	// 	router.Get("", controllers.FindDetailById)
-	// 	//router.Patch("", controllers.UpdateDetail)
-	// 	router.Delete("", controllers.DeleteDetail)
-	// })
+	// --- Admin Routes ---
+	// Without a token the admin API is not served at all
+	if config.AdminToken == "" {
+		log.Println("ADMIN_TOKEN is empty, the /__admin routes are disabled")
+	} else {
+		micro.Route("/__admin", func(router fiber.Router) {
+			router.Use(middleware.RequireAdminToken(config.AdminToken))
+
+			router.Post("/reset", admin.ResetHandler)
+			router.Post("/seed", admin.SeedHandler)
+			router.Get("/snapshot", admin.SnapshotHandler)
+			router.Post("/restore", admin.RestoreHandler)
+
+			router.Post("/cooperatives", cooperatives.CreateCooperativeHandler)
+			router.Get("/cooperatives", cooperatives.FindCooperativesHandler)
+			router.Get("/cooperatives/:coopId", cooperatives.GetCooperativeHandler)
+			router.Patch("/cooperatives/:coopId", cooperatives.UpdateCooperativeHandler)
+			router.Delete("/cooperatives/:coopId", cooperatives.DeleteCooperativeHandler)
+
+			router.Get("/faults", faultRules.FindFaultsHandler)
+			router.Post("/faults", faultRules.SaveFaultHandler)
+			router.Delete("/faults", faultRules.ResetFaultsHandler)
+			router.Delete("/faults/:id", faultRules.DeleteFaultHandler)
+
+			router.Get("/latency", latencySettings.GetLatencyHandler)
+			router.Put("/latency", latencySettings.UpdateLatencyHandler)
+
+			router.Get("/scenarios", scenarioScripts.FindScenariosHandler)
+			router.Post("/scenarios", scenarioScripts.SaveScenarioHandler)
+			router.Delete("/scenarios", scenarioScripts.ClearScenariosHandler)
+			router.Post("/scenarios/reset", scenarioScripts.ResetScenariosHandler)
+		})
+	}
 
 	// // --- Notes Routes ---
 	// micro.Route("/notes", func(router fiber.Router) {
@@ -84,13 +155,97 @@ func main() {
 	// 	})
 	// })
 
+	// Simulated ERP approval of pending registrations
+	services.NewPromotionEngine(repos.Farmers, &config).Start(context.Background())
+
 	log.Fatal(app.Listen(":8000"))
 }
 
+var (
+	config    initializers.Config
+	repos     *repository.Repositories
+	geography *services.Geography
+	kycTypes  *services.KycCatalogue
+	faults    *middleware.FaultInjector
+	latency   *middleware.Latency
+	scenarios *middleware.Scenarios
+	// Proxy or replay middleware of ERP_MODE, nil in mock mode
+	erpTraffic fiber.Handler
+)
+
 func init() {
-	config, err := initializers.LoadConfig(".")
+	var err error
+	config, err = initializers.LoadConfig(".")
 	if err != nil {
 		log.Fatalln("Failed to load environment variables! \n", err.Error())
 	}
-	initializers.ConnectDB(&config)
+
+	repos = initializers.ConnectRepositories(&config)
+	initializers.SeedCooperatives(repos.Cooperatives, config.CooperativesSeedFile)
+
+	if config.GeographyFile != "" {
+		geography, err = services.LoadGeography(config.GeographyFile)
+		if errors.Is(err, os.ErrNotExist) {
+			log.Printf("Geography file %s not found, farmer addresses are not checked", config.GeographyFile)
+		} else if err != nil {
+			log.Fatalln("Failed to load the geography master data! \n", err.Error())
+		}
+	}
+
+	if config.KycTypesFile != "" {
+		kycTypes, err = services.LoadKycCatalogue(config.KycTypesFile)
+	} else {
+		kycTypes, err = services.NewKycCatalogue(services.DefaultKycTypes)
+	}
+	if err != nil {
+		log.Fatalln("Failed to load the KYC type catalogue! \n", err.Error())
+	}
+
+	faults = middleware.NewFaultInjector(config.FaultTimeout)
+	if config.FaultRulesFile != "" {
+		if err := faults.LoadFile(config.FaultRulesFile); err != nil {
+			log.Fatalln("Failed to load the fault rules! \n", err.Error())
+		}
+	}
+
+	latency = middleware.NewLatency()
+	if config.LatencyRulesFile != "" {
+		err = latency.LoadFile(config.LatencyRulesFile)
+		if errors.Is(err, os.ErrNotExist) {
+			log.Printf("Latency rules file %s not found, responses are not delayed", config.LatencyRulesFile)
+		} else if err != nil {
+			log.Fatalln("Failed to load the latency rules! \n", err.Error())
+		}
+	}
+	latency.SetEnabled(config.LatencyEnabled)
+
+	scenarios = middleware.NewScenarios()
+	if config.ScenariosPath != "" {
+		if err := scenarios.LoadPath(config.ScenariosPath); err != nil {
+			log.Fatalln("Failed to load the scenarios! \n", err.Error())
+		}
+	}
+
+	switch config.ErpMode {
+	case "mock":
+	case "proxy":
+		if config.ErpUpstreamURL == "" {
+			log.Fatalln("ERP_MODE=proxy needs ERP_UPSTREAM_URL")
+		}
+		recorder, err := middleware.NewRecorder(config.ErpUpstreamURL, config.ErpRecordingsFile, config.ErpUpstreamTimeout)
+		if err != nil {
+			log.Fatalln("Failed to open the ERP recordings file! \n", err.Error())
+		}
+		erpTraffic = recorder.Handler
+		log.Printf("Proxying /spic_to_erp to %s, recording to %s", config.ErpUpstreamURL, config.ErpRecordingsFile)
+	case "replay":
+		replayer, err := middleware.LoadReplayer(config.ErpRecordingsFile)
+		if err != nil {
+			log.Fatalln("Failed to load the ERP recordings! \n", err.Error())
+		}
+		erpTraffic = replayer.Handler
+		log.Printf("Replaying /spic_to_erp from %s", config.ErpRecordingsFile)
+	default:
+		log.Fatalf("Unknown ERP_MODE %q, expected mock, proxy or replay", config.ErpMode)
+	}
 }
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// AdminTokenHeader is an alternative to "Authorization: Bearer <token>"
+const AdminTokenHeader = "X-Admin-Token"
+
+// RequireAdminToken rejects admin requests that do not carry token, either
+// as a bearer token or in the X-Admin-Token header. An empty token rejects
+// every request.
+func RequireAdminToken(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sent := c.Get(AdminTokenHeader)
+		if bearer, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok {
+			sent = bearer
+		}
+		if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorFarmerResponse{
+				Success: false,
+				Code:    models.ErrCodeUnauthorized,
+				Message: "A valid admin token is required.",
+			})
+		}
+		return c.Next()
+	}
+}
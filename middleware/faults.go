@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// FaultHeader forces a fault on a single request, bypassing the rules:
+// "status:503", "malformed", "reset" or "timeout:5000" (milliseconds)
+const FaultHeader = "X-Mock-Fault"
+
+// FaultInjector makes requests fail on purpose, following rules loaded from
+// a file at startup, managed through the admin API, or forced per request
+// with the X-Mock-Fault header
+type FaultInjector struct {
+	mu           sync.RWMutex
+	rules        []faultRule
+	nextID       int
+	defaultDelay time.Duration
+	random       func() float64
+}
+
+// faultRule is a rule with its Route compiled
+type faultRule struct {
+	models.FaultRule
+	route routePattern
+}
+
+// NewFaultInjector returns an injector without rules. defaultDelay is how
+// long timeout faults hold a request when the rule has no DelayMs.
+func NewFaultInjector(defaultDelay time.Duration) *FaultInjector {
+	return &FaultInjector{nextID: 1, defaultDelay: defaultDelay, random: rand.Float64}
+}
+
+// LoadFile adds the rules of a JSON array of FaultRule
+func (f *FaultInjector) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var rules []models.FaultRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, rule := range rules {
+		if errs := models.ValidateStruct(&rule); len(errs) > 0 {
+			return fmt.Errorf("%s: fault rule %q: %s", path, rule.ID, errs[0].Message)
+		}
+		f.Add(rule)
+	}
+	return nil
+}
+
+// Add stores the rule, replacing the one with the same ID. A rule without
+// an ID gets one.
+func (f *FaultInjector) Add(rule models.FaultRule) models.FaultRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if rule.ID == "" {
+		rule.ID = "f" + strconv.Itoa(f.nextID)
+		f.nextID++
+	}
+	stored := faultRule{FaultRule: rule, route: compileRoute(rule.Route)}
+	for i := range f.rules {
+		if f.rules[i].ID == rule.ID {
+			f.rules[i] = stored
+			return rule
+		}
+	}
+	f.rules = append(f.rules, stored)
+	return rule
+}
+
+// Rules returns the rules in the order they are evaluated
+func (f *FaultInjector) Rules() []models.FaultRule {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	rules := make([]models.FaultRule, len(f.rules))
+	for i := range f.rules {
+		rules[i] = f.rules[i].FaultRule
+	}
+	return rules
+}
+
+// Remove deletes the rule with the given ID and reports whether it existed
+func (f *FaultInjector) Remove(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range f.rules {
+		if f.rules[i].ID == id {
+			f.rules = append(f.rules[:i], f.rules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Reset deletes every rule
+func (f *FaultInjector) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = nil
+}
+
+// Handler is the Fiber middleware
+func (f *FaultInjector) Handler(c *fiber.Ctx) error {
+	if isAdminPath(c.Path()) {
+		return c.Next()
+	}
+
+	if header := c.Get(FaultHeader); header != "" {
+		rule, err := parseFaultHeader(header)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorFarmerResponse{
+				Success: false,
+				Code:    models.ErrCodeBadRequest,
+				Message: err.Error(),
+			})
+		}
+		return f.inject(c, rule)
+	}
+
+	if rule, ok := f.pick(c.Method(), c.Path()); ok {
+		return f.inject(c, rule)
+	}
+	return c.Next()
+}
+
+// pick returns the first rule matching the request whose dice roll hits
+func (f *FaultInjector) pick(method string, path string) (models.FaultRule, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	coopID := coopIDFromPath(path)
+	for _, rule := range f.rules {
+		if rule.Method != "" && !strings.EqualFold(rule.Method, method) {
+			continue
+		}
+		if rule.CoopID != "" && rule.CoopID != coopID {
+			continue
+		}
+		if !rule.route.match(path) {
+			continue
+		}
+		if f.random()*100 < rule.Percent {
+			return rule.FaultRule, true
+		}
+	}
+	return models.FaultRule{}, false
+}
+
+func (f *FaultInjector) inject(c *fiber.Ctx, rule models.FaultRule) error {
+	switch rule.Type {
+	case models.FaultStatus:
+		return c.Status(rule.Status).JSON(models.ErrorFarmerResponse{
+			Success: false,
+			Code:    models.ErrCodeInjectedFault,
+			Message: "Simulated ERP failure (" + strconv.Itoa(rule.Status) + ").",
+		})
+
+	case models.FaultMalformed:
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.Status(fiber.StatusOK).SendString(`{"success":true,"data":{"tempERPCustomerId":"`)
+
+	case models.FaultReset:
+		// A zero linger makes the close send a TCP reset. The connection
+		// handed to the hijack handler is a wrapper, so take the TCP one
+		// from the request context.
+		if tcp, ok := c.Context().Conn().(*net.TCPConn); ok {
+			tcp.SetLinger(0)
+		}
+		c.Context().HijackSetNoResponse(true)
+		c.Context().Hijack(func(conn net.Conn) {
+			conn.Close()
+		})
+		return nil
+
+	case models.FaultTimeout:
+		delay := f.defaultDelay
+		if rule.DelayMs > 0 {
+			delay = time.Duration(rule.DelayMs) * time.Millisecond
+		}
+		wait(c, delay)
+		return c.Status(fiber.StatusGatewayTimeout).JSON(models.ErrorFarmerResponse{
+			Success: false,
+			Code:    models.ErrCodeInjectedFault,
+			Message: "Simulated ERP timeout after " + delay.String() + ".",
+		})
+	}
+	return c.Next()
+}
+
+// wait holds the request for d. A server shutting down releases it at once
+// rather than waiting for the held requests.
+func wait(c *fiber.Ctx, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-c.Context().Done():
+	case <-timer.C:
+	}
+}
+
+func parseFaultHeader(header string) (models.FaultRule, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(header), ":")
+	rule := models.FaultRule{Type: kind, Percent: 100}
+
+	switch kind {
+	case models.FaultStatus:
+		status, err := strconv.Atoi(arg)
+		if err != nil || status < 400 || status > 599 {
+			return rule, fmt.Errorf("%s: expected status:<400-599>, got %q", FaultHeader, header)
+		}
+		rule.Status = status
+	case models.FaultTimeout:
+		if arg != "" {
+			delayMs, err := strconv.Atoi(arg)
+			if err != nil || delayMs < 0 {
+				return rule, fmt.Errorf("%s: expected timeout:<milliseconds>, got %q", FaultHeader, header)
+			}
+			rule.DelayMs = delayMs
+		}
+	case models.FaultMalformed, models.FaultReset:
+	default:
+		return rule, fmt.Errorf("%s: unknown fault %q, expected status, malformed, reset or timeout", FaultHeader, kind)
+	}
+	return rule, nil
+}
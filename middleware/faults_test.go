@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// newFaultApp serves every farmer route with 200 behind the injector
+func newFaultApp(faults *FaultInjector) *fiber.App {
+	app := fiber.New()
+	app.Use(faults.Handler)
+	app.All("/*", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+// requestCode sends the request and returns the status and error code
+func requestCode(t *testing.T, app *fiber.App, method, path string, header map[string]string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	resp, err := app.Test(req, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var response models.ErrorFarmerResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return resp.StatusCode, response.Code
+}
+
+func TestFaultRules(t *testing.T) {
+	faults := NewFaultInjector(time.Second)
+	faults.Add(models.FaultRule{Method: "POST", Route: "/spic_to_erp/customers/*/farmers", CoopID: "COOP001", Percent: 100, Type: models.FaultStatus, Status: 503})
+	app := newFaultApp(faults)
+
+	tests := []struct {
+		name         string
+		method, path string
+		status       int
+		code         string
+	}{
+		{"matching request", fiber.MethodPost, "/spic_to_erp/customers/COOP001/farmers", fiber.StatusServiceUnavailable, models.ErrCodeInjectedFault},
+		{"other method", fiber.MethodGet, "/spic_to_erp/customers/COOP001/farmers", fiber.StatusOK, ""},
+		{"other cooperative", fiber.MethodPost, "/spic_to_erp/customers/COOP002/farmers", fiber.StatusOK, ""},
+		{"other route", fiber.MethodPost, "/spic_to_erp/vendors/COOP001/farmers", fiber.StatusOK, ""},
+		{"admin API", fiber.MethodPost, "/__admin/spic_to_erp/customers/COOP001/farmers", fiber.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, code := requestCode(t, app, tt.method, tt.path, nil); status != tt.status || code != tt.code {
+				t.Errorf("got %d %q, want %d %q", status, code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestFaultHeader(t *testing.T) {
+	app := newFaultApp(NewFaultInjector(time.Second))
+
+	tests := []struct {
+		header string
+		status int
+		code   string
+	}{
+		{"status:502", fiber.StatusBadGateway, models.ErrCodeInjectedFault},
+		{"timeout:10", fiber.StatusGatewayTimeout, models.ErrCodeInjectedFault},
+		{"status:200", fiber.StatusBadRequest, models.ErrCodeBadRequest},
+		{"timeout:soon", fiber.StatusBadRequest, models.ErrCodeBadRequest},
+		{"explode", fiber.StatusBadRequest, models.ErrCodeBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			status, code := requestCode(t, app, fiber.MethodGet, "/spic_to_erp/customers/COOP001/farmers", map[string]string{FaultHeader: tt.header})
+			if status != tt.status || code != tt.code {
+				t.Errorf("got %d %q, want %d %q", status, code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+// Replacing a rule replaces its compiled route too
+func TestFaultRuleReplaced(t *testing.T) {
+	faults := NewFaultInjector(time.Second)
+	faults.Add(models.FaultRule{ID: "f1", Route: "/spic_to_erp/customers/*", Percent: 100, Type: models.FaultStatus, Status: 503})
+	faults.Add(models.FaultRule{ID: "f1", Route: "/spic_to_erp/vendors/*", Percent: 100, Type: models.FaultStatus, Status: 503})
+	app := newFaultApp(faults)
+
+	if status, _ := requestCode(t, app, fiber.MethodGet, "/spic_to_erp/customers/COOP001/farmers", nil); status != fiber.StatusOK {
+		t.Errorf("old route got %d, want 200", status)
+	}
+	if status, _ := requestCode(t, app, fiber.MethodGet, "/spic_to_erp/vendors/COOP001/farmers", nil); status != fiber.StatusServiceUnavailable {
+		t.Errorf("new route got %d, want 503", status)
+	}
+}
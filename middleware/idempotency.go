@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client's key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses replayed from the store
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+type idempotentResponse struct {
+	bodyHash    [sha256.Size]byte
+	done        chan struct{} // closed once the first request has completed
+	stored      bool          // false when the first request failed and is not replayed
+	status      int
+	contentType []byte
+	body        []byte
+	expires     time.Time
+}
+
+// Idempotency replays the stored response of the first request made with an
+// Idempotency-Key, byte for byte, to every later request on the same path
+// with the same key and body until ttl has passed. Reusing a key with a
+// different body answers 409 IDEMPOTENCY_KEY_REUSED. Requests without the
+// header and 5xx responses are not stored.
+type Idempotency struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*idempotentResponse
+}
+
+func NewIdempotency(ttl time.Duration) *Idempotency {
+	return &Idempotency{ttl: ttl, entries: map[string]*idempotentResponse{}}
+}
+
+// Reset forgets every stored response
+func (m *Idempotency) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = map[string]*idempotentResponse{}
+}
+
+// Handler is the Fiber middleware
+func (m *Idempotency) Handler(c *fiber.Ctx) error {
+	key := c.Get(IdempotencyKeyHeader)
+	if key == "" {
+		return c.Next()
+	}
+	scopedKey := c.Method() + " " + c.Path() + " " + key
+	bodyHash := sha256.Sum256(c.Body())
+
+	for {
+		entry, first := m.claim(scopedKey, bodyHash)
+		if first {
+			return m.record(c, scopedKey, entry)
+		}
+
+		// A concurrent request with the same key is still running: wait for
+		// its response instead of running the handler twice
+		<-entry.done
+		if !entry.stored {
+			continue
+		}
+		if entry.bodyHash != bodyHash {
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorFarmerResponse{
+				Success: false,
+				Code:    models.ErrCodeIdempotencyKeyReused,
+				Message: "The Idempotency-Key " + key + " was already used with a different request body.",
+			})
+		}
+		c.Status(entry.status)
+		c.Response().Header.SetContentTypeBytes(entry.contentType)
+		c.Set(IdempotentReplayedHeader, "true")
+		return c.Send(entry.body)
+	}
+}
+
+// claim returns the live entry for key, or registers a new in-flight one
+// and reports that the caller runs the request
+func (m *Idempotency) claim(key string, bodyHash [sha256.Size]byte) (*idempotentResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := m.entries[key]; ok && (entry.expires.IsZero() || now.Before(entry.expires)) {
+		return entry, false
+	}
+	for k, entry := range m.entries {
+		if !entry.expires.IsZero() && !now.Before(entry.expires) {
+			delete(m.entries, k)
+		}
+	}
+
+	entry := &idempotentResponse{bodyHash: bodyHash, done: make(chan struct{})}
+	m.entries[key] = entry
+	return entry, true
+}
+
+func (m *Idempotency) record(c *fiber.Ctx, key string, entry *idempotentResponse) error {
+	// Release the entry unless its response was stored, even when the
+	// handler panics, so requests waiting on it never hang
+	defer func() {
+		if !entry.stored {
+			m.release(key, entry)
+		}
+	}()
+
+	if err := c.Next(); err != nil {
+		// Let the error handler write the response, then store what it wrote
+		if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+			return handlerErr
+		}
+	}
+
+	status := c.Response().StatusCode()
+	if status >= fiber.StatusInternalServerError {
+		return nil
+	}
+
+	m.mu.Lock()
+	entry.stored = true
+	entry.status = status
+	entry.contentType = bytes.Clone(c.Response().Header.ContentType())
+	entry.body = bytes.Clone(c.Response().Body())
+	entry.expires = time.Now().Add(m.ttl)
+	m.mu.Unlock()
+	close(entry.done)
+	return nil
+}
+
+// release drops an entry whose response is not replayed, so the next
+// request with the key runs the handler again
+func (m *Idempotency) release(key string, entry *idempotentResponse) {
+	m.mu.Lock()
+	if m.entries[key] == entry {
+		delete(m.entries, key)
+	}
+	m.mu.Unlock()
+	close(entry.done)
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+)
+
+// newIdempotentApp serves POST /farmers behind the middleware with handler,
+// counting how often the handler ran
+func newIdempotentApp(handler fiber.Handler) (*fiber.App, *int) {
+	calls := 0
+	app := fiber.New()
+	app.Use(recover.New())
+	app.Use(NewIdempotency(time.Minute).Handler)
+	app.Post("/farmers", func(c *fiber.Ctx) error {
+		calls++
+		return handler(c)
+	})
+	return app, &calls
+}
+
+func postIdempotent(t *testing.T, app *fiber.App, key, body string) (int, string, string) {
+	req := httptest.NewRequest(fiber.MethodPost, "/farmers", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set(IdempotencyKeyHeader, key)
+	resp, err := app.Test(req, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, resp.Header.Get(IdempotentReplayedHeader), string(data)
+}
+
+func TestIdempotencyReplaysSameBody(t *testing.T) {
+	app, calls := newIdempotentApp(func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusCreated).SendString("created " + time.Now().String())
+	})
+
+	status, _, first := postIdempotent(t, app, "k1", `{"farmerId":"F1"}`)
+	replayStatus, replayed, again := postIdempotent(t, app, "k1", `{"farmerId":"F1"}`)
+	if status != fiber.StatusCreated || replayStatus != status || again != first {
+		t.Errorf("replayed %d %q, want %d %q", replayStatus, again, status, first)
+	}
+	if replayed != "true" {
+		t.Errorf("replay lacks %s", IdempotentReplayedHeader)
+	}
+	if *calls != 1 {
+		t.Errorf("handler ran %d times, want 1", *calls)
+	}
+}
+
+func TestIdempotencyRejectsKeyReuse(t *testing.T) {
+	app, calls := newIdempotentApp(func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	postIdempotent(t, app, "k1", `{"farmerId":"F1"}`)
+	if status, _, body := postIdempotent(t, app, "k1", `{"farmerId":"F2"}`); status != fiber.StatusConflict || !strings.Contains(body, "IDEMPOTENCY_KEY_REUSED") {
+		t.Errorf("reused key got %d %s, want 409 IDEMPOTENCY_KEY_REUSED", status, body)
+	}
+	if *calls != 1 {
+		t.Errorf("handler ran %d times, want 1", *calls)
+	}
+}
+
+func TestIdempotencySkipsServerErrors(t *testing.T) {
+	status := fiber.StatusServiceUnavailable
+	app, calls := newIdempotentApp(func(c *fiber.Ctx) error {
+		return c.SendStatus(status)
+	})
+
+	postIdempotent(t, app, "k1", `{"farmerId":"F1"}`)
+	status = fiber.StatusCreated
+	if got, replayed, _ := postIdempotent(t, app, "k1", `{"farmerId":"F1"}`); got != fiber.StatusCreated || replayed != "" {
+		t.Errorf("retry after a 503 got %d (replayed %q), want the handler's 201", got, replayed)
+	}
+	if *calls != 2 {
+		t.Errorf("handler ran %d times, want 2", *calls)
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterPanic(t *testing.T) {
+	panics := true
+	app, calls := newIdempotentApp(func(c *fiber.Ctx) error {
+		if panics {
+			panic("handler failed")
+		}
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	postIdempotent(t, app, "k1", `{"farmerId":"F1"}`)
+	panics = false
+	if status, _, _ := postIdempotent(t, app, "k1", `{"farmerId":"F1"}`); status != fiber.StatusCreated {
+		t.Errorf("retry after a panic got %d, want 201", status)
+	}
+	if *calls != 2 {
+		t.Errorf("handler ran %d times, want 2", *calls)
+	}
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// Latency delays responses the way the real ERP does, so client timeouts
+// and retry backoff can be tested against the mock
+type Latency struct {
+	mu       sync.RWMutex
+	settings models.LatencySettings
+	routes   []routePattern // the compiled Route of each rule
+}
+
+// NewLatency returns a disabled Latency without rules
+func NewLatency() *Latency {
+	return &Latency{}
+}
+
+// LoadFile replaces the rules with the JSON array of LatencyRule in path
+func (l *Latency) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	settings := l.Settings()
+	if err := json.Unmarshal(data, &settings.Rules); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if errs := models.ValidateStruct(&settings); len(errs) > 0 {
+		return fmt.Errorf("%s: %s", path, errs[0].Message)
+	}
+	l.Set(settings)
+	return nil
+}
+
+// Settings returns a copy of the current settings
+func (l *Latency) Settings() models.LatencySettings {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	settings := l.settings
+	settings.Rules = append([]models.LatencyRule{}, l.settings.Rules...)
+	return settings
+}
+
+// Set replaces the settings
+func (l *Latency) Set(settings models.LatencySettings) {
+	routes := make([]routePattern, len(settings.Rules))
+	for i, rule := range settings.Rules {
+		routes[i] = compileRoute(rule.Route)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.settings, l.routes = settings, routes
+}
+
+// SetEnabled turns the delays on or off, keeping the rules
+func (l *Latency) SetEnabled(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.settings.Enabled = enabled
+}
+
+// Handler is the Fiber middleware
+func (l *Latency) Handler(c *fiber.Ctx) error {
+	if isAdminPath(c.Path()) {
+		return c.Next()
+	}
+	if delay := l.delay(c.Method(), c.Path()); delay > 0 {
+		wait(c, delay)
+	}
+	return c.Next()
+}
+
+// delay draws the delay of the first rule matching the request
+func (l *Latency) delay(method string, path string) time.Duration {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if !l.settings.Enabled {
+		return 0
+	}
+	for i, rule := range l.settings.Rules {
+		if rule.Method != "" && !strings.EqualFold(rule.Method, method) {
+			continue
+		}
+		if !l.routes[i].match(path) {
+			continue
+		}
+		return sampleLatency(rule)
+	}
+	return 0
+}
+
+func sampleLatency(rule models.LatencyRule) time.Duration {
+	var ms float64
+	switch rule.Distribution {
+	case models.LatencyFixed:
+		ms = float64(rule.DelayMs)
+	case models.LatencyUniform:
+		ms = float64(rule.MinMs) + rand.Float64()*float64(rule.MaxMs-rule.MinMs)
+	case models.LatencyNormal:
+		ms = float64(rule.MeanMs) + rand.NormFloat64()*float64(rule.StdDevMs)
+	}
+	if ms < 0 {
+		ms = 0
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"regexp"
+	"strings"
+)
+
+var coopPathPattern = regexp.MustCompile(`^/spic_to_erp/(?:customers|vendors)/([^/]+)`)
+
+// coopIDFromPath extracts the coopId of a farmer route. The middlewares run
+// before routing, so route params are not available yet.
+func coopIDFromPath(path string) string {
+	if m := coopPathPattern.FindStringSubmatch(path); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// routePattern matches paths against the Route of a rule, where * matches
+// any run of characters, slashes included. The zero value, for an empty
+// Route, matches every path.
+type routePattern struct {
+	re *regexp.Regexp
+}
+
+// compileRoute compiles a Route once, when its rule is added
+func compileRoute(route string) routePattern {
+	if route == "" {
+		return routePattern{}
+	}
+	parts := strings.Split(route, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	// Every part is quoted, so the expression always compiles
+	return routePattern{re: regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")}
+}
+
+func (p routePattern) match(path string) bool {
+	return p.re == nil || p.re.MatchString(path)
+}
+
+// isAdminPath keeps the admin API and the Swagger UI out of simulations, so
+// they can always be used to turn them off
+func isAdminPath(path string) bool {
+	return strings.HasPrefix(path, "/__admin") || strings.HasPrefix(path, "/swagger")
+}
+
+var farmerPathPattern = regexp.MustCompile(`^/spic_to_erp/(?:customers|vendors)/[^/]+/farmers/([^/]+)`)
+
+// farmerIDFromPath extracts the farmerId of a single-farmer route
+func farmerIDFromPath(path string) string {
+	if m := farmerPathPattern.FindStringSubmatch(path); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/proxy"
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// erpPrefix is the part of the API that is proxied and replayed
+const erpPrefix = "/spic_to_erp/"
+
+// Response headers that describe the connection rather than the response
+var hopHeaders = map[string]bool{
+	fiber.HeaderConnection:       true,
+	fiber.HeaderContentLength:    true,
+	fiber.HeaderDate:             true,
+	fiber.HeaderServer:           true,
+	fiber.HeaderTransferEncoding: true,
+}
+
+// Recorder forwards ERP requests to the real ERP and appends every request
+// and response to a JSONL file, to be served later by a Replayer
+type Recorder struct {
+	upstream string
+	timeout  time.Duration
+	mu       sync.Mutex
+	file     *os.File
+}
+
+// NewRecorder forwards to upstream, a base URL such as http://erp:8080, and
+// appends to the recordings file at path
+func NewRecorder(upstream string, path string, timeout time.Duration) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{upstream: strings.TrimSuffix(upstream, "/"), timeout: timeout, file: file}, nil
+}
+
+// Handler is the Fiber middleware
+func (r *Recorder) Handler(c *fiber.Ctx) error {
+	if !strings.HasPrefix(c.Path(), erpPrefix) {
+		return c.Next()
+	}
+
+	// The recordings store bodies as text, so ask the ERP for an
+	// uncompressed response
+	c.Request().Header.Del(fiber.HeaderAcceptEncoding)
+
+	start := time.Now()
+	if err := proxy.DoTimeout(c, r.upstream+c.OriginalURL(), r.timeout); err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(models.ErrorFarmerResponse{
+			Success: false,
+			Code:    models.ErrCodeUpstream,
+			Message: "The upstream ERP could not be reached: " + err.Error(),
+		})
+	}
+
+	recording := models.Recording{
+		RecordedAt:   start.UTC(),
+		Method:       c.Method(),
+		Path:         c.OriginalURL(),
+		RequestBody:  string(c.Body()),
+		Status:       c.Response().StatusCode(),
+		Headers:      map[string]string{},
+		ResponseBody: string(c.Response().Body()),
+		DurationMs:   time.Since(start).Milliseconds(),
+	}
+	c.Response().Header.VisitAll(func(key []byte, value []byte) {
+		if !hopHeaders[string(key)] {
+			recording.Headers[string(key)] = string(value)
+		}
+	})
+	return r.write(recording)
+}
+
+func (r *Recorder) write(recording models.Recording) error {
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(recording); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err := r.file.Write(line.Bytes())
+	return err
+}
+
+// Replayer serves recorded ERP responses to requests with the same method,
+// path and normalized body. Identical requests get their recordings in
+// order, then the last one again. Requests without a recording reach the
+// normal handlers.
+type Replayer struct {
+	mu         sync.Mutex
+	recordings map[string][]models.Recording
+	served     map[string]int
+}
+
+// LoadReplayer reads the recordings JSONL file at path
+func LoadReplayer(path string) (*Replayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := &Replayer{recordings: map[string][]models.Recording{}, served: map[string]int{}}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var recording models.Recording
+		if err := json.Unmarshal(scanner.Bytes(), &recording); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		key := recordingKey(recording.Method, recording.Path, []byte(recording.RequestBody))
+		r.recordings[key] = append(r.recordings[key], recording)
+	}
+	return r, scanner.Err()
+}
+
+// Handler is the Fiber middleware
+func (r *Replayer) Handler(c *fiber.Ctx) error {
+	if !strings.HasPrefix(c.Path(), erpPrefix) {
+		return c.Next()
+	}
+
+	recording, ok := r.next(recordingKey(c.Method(), c.OriginalURL(), c.Body()))
+	if !ok {
+		return c.Next()
+	}
+	for name, value := range recording.Headers {
+		c.Set(name, value)
+	}
+	return c.Status(recording.Status).SendString(recording.ResponseBody)
+}
+
+func (r *Replayer) next(key string) (models.Recording, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recordings := r.recordings[key]
+	if len(recordings) == 0 {
+		return models.Recording{}, false
+	}
+	index := min(r.served[key], len(recordings)-1)
+	r.served[key]++
+	return recordings[index], true
+}
+
+// recordingKey identifies a request regardless of query parameter order,
+// JSON key order and whitespace
+func recordingKey(method string, originalURL string, body []byte) string {
+	path, query, _ := strings.Cut(originalURL, "?")
+	if values, err := url.ParseQuery(query); err == nil {
+		query = values.Encode()
+	}
+	return strings.ToUpper(method) + " " + path + "?" + query + " " + normalizeBody(body)
+}
+
+func normalizeBody(body []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return string(bytes.TrimSpace(body))
+	}
+	// Maps marshal with sorted keys
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return string(bytes.TrimSpace(body))
+	}
+	return string(normalized)
+}
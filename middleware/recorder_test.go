@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const erpResponse = `{"success":true,"data":{"tempERPCustomerId":"TEMP-CUST-1"}}`
+
+// gzipERP answers every request with erpResponse, gzipped when the client
+// accepts it, the way most real servers do
+func gzipERP(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, erpResponse)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusCreated)
+		gz := gzip.NewWriter(w)
+		io.WriteString(gz, erpResponse)
+		gz.Close()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func postERP(t *testing.T, app *fiber.App, body string) (int, http.Header, string) {
+	req := httptest.NewRequest(fiber.MethodPost, "/spic_to_erp/customers/COOP001/farmers?b=2&a=1", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set(fiber.HeaderAcceptEncoding, "gzip, deflate")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, resp.Header, string(data)
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recordings.jsonl")
+
+	recorder, err := NewRecorder(gzipERP(t).URL, path, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxied := fiber.New()
+	proxied.Use(recorder.Handler)
+	if status, _, body := postERP(t, proxied, `{"farmerId":"F1", "firstName":"Ravi"}`); status != fiber.StatusCreated || body != erpResponse {
+		t.Fatalf("proxied %d %q, want 201 %q", status, body, erpResponse)
+	}
+
+	replayer, err := LoadReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := fiber.New()
+	replayed.Use(replayer.Handler)
+	replayed.Use(func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusTeapot)
+	})
+
+	// Same request with its JSON keys reordered
+	status, header, body := postERP(t, replayed, `{"firstName":"Ravi","farmerId":"F1"}`)
+	if status != fiber.StatusCreated || body != erpResponse {
+		t.Errorf("replayed %d %q, want 201 %q", status, body, erpResponse)
+	}
+	if encoding := header.Get(fiber.HeaderContentEncoding); encoding != "" {
+		t.Errorf("replayed Content-Encoding %q for a plain body", encoding)
+	}
+
+	if status, _, _ := postERP(t, replayed, `{"farmerId":"F2"}`); status != fiber.StatusTeapot {
+		t.Errorf("request without a recording got %d, want it passed on", status)
+	}
+}
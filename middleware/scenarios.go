@@ -0,0 +1,270 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"gopkg.in/yaml.v3"
+)
+
+// ScenarioHeader names, on a scripted response, the scenario that served it
+const ScenarioHeader = "X-Mock-Scenario"
+
+// Scenarios serves scripted response sequences for deterministic tests,
+// such as "the first create of farmer X fails with 502, the retry works".
+// Requests no scenario matches reach the normal handlers.
+type Scenarios struct {
+	mu     sync.Mutex
+	loaded []models.Scenario
+	states []*models.ScenarioState
+	routes map[string]routePattern // compiled by Route as scenarios are added
+	nextID int
+}
+
+// NewScenarios returns an engine without scenarios
+func NewScenarios() *Scenarios {
+	return &Scenarios{nextID: 1, routes: map[string]routePattern{}}
+}
+
+// ParseScenarios decodes a YAML or JSON list of scenarios
+func ParseScenarios(data []byte) ([]models.Scenario, error) {
+	var scenarios []models.Scenario
+	if err := yaml.Unmarshal(data, &scenarios); err != nil {
+		return nil, err
+	}
+	return scenarios, nil
+}
+
+// LoadPath loads the scenarios of a .yaml, .yml or .json file, or of every
+// such file in a directory in name order. They are restored by Reset. An ID
+// may only be used once across the files.
+func (s *Scenarios) LoadPath(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".yaml", ".yml", ".json":
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	definedIn := map[string]string{} // file of each scenario ID
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		scenarios, err := ParseScenarios(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		for _, scenario := range scenarios {
+			if errs := models.ValidateStruct(&scenario); len(errs) > 0 {
+				return fmt.Errorf("%s: scenario %q: %s", file, scenario.ID, errs[0].Message)
+			}
+			// Add would replace the earlier scenario, leaving both in loaded
+			if earlier, ok := definedIn[scenario.ID]; ok {
+				return fmt.Errorf("%s: scenario %q is already defined in %s", file, scenario.ID, earlier)
+			}
+			scenario = s.Add(scenario).Scenario
+			definedIn[scenario.ID] = file
+			s.loaded = append(s.loaded, scenario)
+		}
+	}
+	return nil
+}
+
+// Add stores the scenario, replacing the one with the same ID and its
+// progress. A scenario without an ID gets one.
+func (s *Scenarios) Add(scenario models.Scenario) models.ScenarioState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if scenario.ID == "" {
+		scenario.ID = "s" + strconv.Itoa(s.nextID)
+		s.nextID++
+	}
+	if scenario.AfterLast == "" {
+		scenario.AfterLast = models.ScenarioPassthrough
+	}
+	if _, ok := s.routes[scenario.Route]; !ok {
+		s.routes[scenario.Route] = compileRoute(scenario.Route)
+	}
+
+	state := &models.ScenarioState{Scenario: scenario}
+	for i := range s.states {
+		if s.states[i].ID == scenario.ID {
+			s.states[i] = state
+			return *state
+		}
+	}
+	s.states = append(s.states, state)
+	return *state
+}
+
+// List returns the scenarios in the order they are matched
+func (s *Scenarios) List() []models.ScenarioState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make([]models.ScenarioState, 0, len(s.states))
+	for _, state := range s.states {
+		states = append(states, *state)
+	}
+	return states
+}
+
+// Clear removes every scenario, including the loaded ones
+func (s *Scenarios) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states = nil
+}
+
+// Reset goes back to the loaded scenarios, none of their responses served
+func (s *Scenarios) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states = make([]*models.ScenarioState, 0, len(s.loaded))
+	for _, scenario := range s.loaded {
+		s.states = append(s.states, &models.ScenarioState{Scenario: scenario})
+	}
+}
+
+// Handler is the Fiber middleware
+func (s *Scenarios) Handler(c *fiber.Ctx) error {
+	if isAdminPath(c.Path()) {
+		return c.Next()
+	}
+
+	var body map[string]any
+	json.Unmarshal(c.Body(), &body)
+
+	farmerID := farmerIDFromPath(c.Path())
+	if farmerID == "" {
+		farmerID, _ = body["farmerId"].(string)
+	}
+
+	id, step, ok := s.next(c.Method(), c.Path(), coopIDFromPath(c.Path()), farmerID, body)
+	if !ok || step.Passthrough {
+		return c.Next()
+	}
+
+	if step.DelayMs > 0 {
+		wait(c, time.Duration(step.DelayMs)*time.Millisecond)
+	}
+	for name, value := range step.Headers {
+		c.Set(name, value)
+	}
+	c.Set(ScenarioHeader, id)
+	c.Status(step.Status)
+
+	switch {
+	case step.Body != nil:
+		return c.JSON(step.Body)
+	case step.Code != "":
+		return c.JSON(models.ErrorFarmerResponse{
+			Success:  false,
+			Code:     step.Code,
+			Message:  step.Message,
+			FarmerId: farmerID,
+		})
+	}
+	return nil
+}
+
+// next finds the first scenario matching the request with a response left,
+// and consumes that response
+func (s *Scenarios) next(method string, path string, coopID string, farmerID string, body map[string]any) (string, models.ScenarioResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, state := range s.states {
+		if !scenarioMatches(&state.Scenario, s.routes[state.Route], method, path, coopID, farmerID, body) {
+			continue
+		}
+
+		index := state.Served
+		if index >= len(state.Responses) {
+			switch state.AfterLast {
+			case models.ScenarioRepeat:
+				index = len(state.Responses) - 1
+			case models.ScenarioLoop:
+				index %= len(state.Responses)
+			default:
+				continue
+			}
+		}
+		state.Served++
+		return state.ID, state.Responses[index], true
+	}
+	return "", models.ScenarioResponse{}, false
+}
+
+func scenarioMatches(scenario *models.Scenario, route routePattern, method string, path string, coopID string, farmerID string, body map[string]any) bool {
+	if scenario.Method != "" && !strings.EqualFold(scenario.Method, method) {
+		return false
+	}
+	if scenario.CoopID != "" && scenario.CoopID != coopID {
+		return false
+	}
+	if scenario.FarmerID != "" && scenario.FarmerID != farmerID {
+		return false
+	}
+	if !route.match(path) {
+		return false
+	}
+	for field, want := range scenario.Body {
+		got, ok := bodyField(body, field)
+		if !ok || !sameJSON(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// bodyField looks up a dotted path such as "address.zipCode" in a JSON body
+func bodyField(body map[string]any, path string) (any, bool) {
+	var value any = body
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// sameJSON compares values by their JSON encoding, so a YAML 5 equals a
+// JSON 5.0
+func sameJSON(a any, b any) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aJSON, bJSON)
+}
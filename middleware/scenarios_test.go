@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const retryScenario = `
+- id: retry
+  method: POST
+  route: /spic_to_erp/customers/*/farmers
+  farmerId: F1
+  body:
+    farmer_kyc_type: PAN
+  responses:
+    - status: 502
+      code: STORAGE_ERROR
+`
+
+// writeScenarios writes each file into a new directory and returns it
+func writeScenarios(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// postScenario posts body and returns the status and the scenario that
+// answered, if any
+func postScenario(t *testing.T, app *fiber.App, path, body string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, resp.Header.Get(ScenarioHeader)
+}
+
+func TestScenarioMatching(t *testing.T) {
+	scenarios := NewScenarios()
+	if err := scenarios.LoadPath(writeScenarios(t, map[string]string{"retry.yaml": retryScenario})); err != nil {
+		t.Fatal(err)
+	}
+	app := fiber.New()
+	app.Use(scenarios.Handler)
+	app.Post("/*", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	const path = "/spic_to_erp/customers/COOP001/farmers"
+	steps := []struct {
+		name     string
+		path     string
+		body     string
+		status   int
+		scenario string
+	}{
+		{"other farmer", path, `{"farmerId":"F2","farmer_kyc_type":"PAN"}`, fiber.StatusCreated, ""},
+		{"other body", path, `{"farmerId":"F1","farmer_kyc_type":"AADHAAR"}`, fiber.StatusCreated, ""},
+		{"other route", "/spic_to_erp/vendors/COOP001/farmers", `{"farmerId":"F1","farmer_kyc_type":"PAN"}`, fiber.StatusCreated, ""},
+		{"match", path, `{"farmerId":"F1","farmer_kyc_type":"PAN"}`, fiber.StatusBadGateway, "retry"},
+		{"after the last response", path, `{"farmerId":"F1","farmer_kyc_type":"PAN"}`, fiber.StatusCreated, ""},
+	}
+	for _, step := range steps {
+		if status, scenario := postScenario(t, app, step.path, step.body); status != step.status || scenario != step.scenario {
+			t.Errorf("%s: got %d from %q, want %d from %q", step.name, status, scenario, step.status, step.scenario)
+		}
+	}
+
+	// Reset serves the loaded sequence again
+	scenarios.Reset()
+	if status, scenario := postScenario(t, app, path, `{"farmerId":"F1","farmer_kyc_type":"PAN"}`); status != fiber.StatusBadGateway || scenario != "retry" {
+		t.Errorf("after reset got %d from %q, want 502 from retry", status, scenario)
+	}
+}
+
+func TestScenarioDuplicateIDs(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string // in the error
+	}{
+		{"across files", map[string]string{"a.yaml": retryScenario, "b.yaml": retryScenario}, []string{"b.yaml", "a.yaml", `"retry"`}},
+		{"in one file", map[string]string{"a.yaml": retryScenario + retryScenario}, []string{"a.yaml", `"retry"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewScenarios().LoadPath(writeScenarios(t, tt.files))
+			if err == nil {
+				t.Fatal("loaded a duplicate scenario ID")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not name %s", err, want)
+				}
+			}
+		})
+	}
+}
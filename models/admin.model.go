@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// SeedFarmer is a farmer registration loaded through the admin seed
+// endpoint. Role defaults to customer.
+// swagger:model SeedFarmer
+type SeedFarmer struct {
+	CoopID string `json:"coopId" example:"COOP001" validate:"required"`
+	Role   string `json:"role,omitempty" example:"customer" validate:"omitempty,oneof=customer vendor"`
+	CreateDetailSchema
+}
+
+// SeedSchema is the JSON body of the admin seed endpoint. Cooperatives are
+// saved first, so farmers may belong to them.
+// swagger:model SeedSchema
+type SeedSchema struct {
+	Cooperatives []CooperativeSchema `json:"cooperatives,omitempty"`
+	Farmers      []SeedFarmer        `json:"farmers,omitempty"`
+}
+
+// Snapshot is the whole state of the mock, as exported and restored by the
+// admin API. Farmers include soft-deleted ones.
+// swagger:model Snapshot
+type Snapshot struct {
+	TakenAt      time.Time       `json:"takenAt"`
+	Cooperatives []Cooperative   `json:"cooperatives"`
+	Clubs        []Club          `json:"clubs"`
+	Farmers      []FarmerDetails `json:"farmers"`
+}
+
+// RestoreResponse counts what a snapshot restore loaded
+type RestoreResponse struct {
+	Success      bool `json:"success"`
+	Cooperatives int  `json:"cooperatives"`
+	Clubs        int  `json:"clubs"`
+	Farmers      int  `json:"farmers"`
+}
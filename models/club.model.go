@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Club groups farmers of a cooperative around a leader. Members registered
+// without their own KYC ID must name a leader who is a registered farmer.
+type Club struct {
+	CoopID         string     `gorm:"primaryKey;size:64" json:"coopId"`
+	ID             string     `gorm:"primaryKey;size:64" json:"clubId"`
+	Name           string     `json:"clubName"`
+	LeaderFarmerID string     `json:"clubLeaderFarmerId"`
+	CreatedAt      *time.Time `gorm:"default:null" json:"createdAt"`
+	UpdatedAt      *time.Time `gorm:"default:null;autoUpdateTime:false" json:"updatedAt"` // set by callers, so restored snapshots keep it
+}
+
+// ClubFarmersResponse lists the members of a club
+type ClubFarmersResponse struct {
+	Club       Club             `json:"club"`
+	Data       []FarmerResponse `json:"data"`
+	Pagination PaginationInfo   `json:"pagination"`
+}
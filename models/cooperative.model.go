@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Cooperative is a cooperative known to the ERP. Farmer routes answer
+// INVALID_COOP for a coopId that is missing or inactive.
+type Cooperative struct {
+	ID        string     `gorm:"primaryKey;size:64" json:"coopId"`
+	Name      string     `gorm:"not null" json:"name"`
+	Active    bool       `json:"active"`
+	CreatedAt *time.Time `gorm:"default:null" json:"createdAt"`
+	UpdatedAt *time.Time `gorm:"default:null;autoUpdateTime:false" json:"updatedAt"` // set by callers, so restored snapshots keep it
+}
+
+// CooperativeSchema is the body of the admin cooperative endpoints, and the
+// shape of each entry in the cooperatives seed file
+// swagger:model CooperativeSchema
+type CooperativeSchema struct {
+	ID     string `json:"coopId" example:"COOP001" validate:"required,max=64"`
+	Name   string `json:"name" example:"Guntur Farmers Cooperative" validate:"required"`
+	Active *bool  `json:"active" example:"true"` // defaults to true on create
+}
+
+type CooperativeResponse struct {
+	Success bool        `json:"success"`
+	Data    Cooperative `json:"data"`
+}
+
+type ListCooperativesResponse struct {
+	Success bool          `json:"success"`
+	Data    []Cooperative `json:"data"`
+}
@@ -18,6 +18,7 @@ type FarmerDetailResponse struct {
 	EntityID					string     `json:"entityId"`
 	CustomerCode				string     `json:"customerCode"`
 	VendorCode					string     `json:"vendorCode"`
+	Status						string     `json:"status"`
 	CreatedDate         			string 	   `json:"createdAt"`
 	UpdatedDate         			string 	   `json:"updatedAt"`
 	// BankDetails         BankDetailsInfo `json:"bankDetails"`
@@ -6,15 +6,70 @@ type CreateSuccessFarmerResponse struct {
 }
 type FarmerResponse struct {
 	TempERPCustomerID string `json:"tempERPCustomerId"`
+	TempERPVendorID   string `json:"tempERPVendorId"`
 	ErpCustomerId     string `json:"erpCustomerId"`
 	ErpVendorId       string `json:"erpVendorId"`
 	FarmerId          string `json:"farmerId"`
 	CreatedAt         string `json:"createdAt"`
 	UpdatedAt         string `json:"updatedAt"`
+	Status            string `json:"status"`
 	Message           string `json:"message"`
+	DeletedAt         string `json:"deletedAt,omitempty"`
+	// ChangedFields lists the JSON fields modified by an update request
+	ChangedFields []string `json:"changedFields,omitempty"`
 }
 
+// ErrorFarmerResponse is the body of every error response. Clients should
+// branch on Code; Message is meant for people.
 type ErrorFarmerResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	Success  bool             `json:"success" example:"false"`
+	Code     string           `json:"code" example:"DUPLICATE_KYC"`
+	Message  string           `json:"message"`
+	FarmerId string           `json:"farmerId,omitempty"`
+	Errors   []*ErrorResponse `json:"errors,omitempty"` // failed field rules, for VALIDATION_ERROR
+}
+
+// Error codes returned in ErrorFarmerResponse.Code
+const (
+	ErrCodeInvalidBody          = "INVALID_BODY"           // 400, the body is not valid JSON for the endpoint
+	ErrCodeInvalidQuery         = "INVALID_QUERY"          // 400, a query parameter cannot be parsed
+	ErrCodeBadRequest           = "BAD_REQUEST"            // other 4xx answered by the router, e.g. 405
+	ErrCodeUnauthorized         = "UNAUTHORIZED"           // 401, admin API only: the admin token is missing or wrong
+	ErrCodeValidation           = "VALIDATION_ERROR"       // 422, see Errors for the failed fields
+	ErrCodeFarmerIDImmutable    = "FARMER_ID_IMMUTABLE"    // 422, an update tried to change farmerId
+	ErrCodeInvalidCoop          = "INVALID_COOP"           // 404, the cooperative is unknown or inactive
+	ErrCodeInvalidKyc           = "INVALID_KYC"            // 422, unknown or mismatched KYC type, or a KYC ID in the wrong format
+	ErrCodeInvalidGeography     = "INVALID_GEOGRAPHY"      // 422, an address ID or zip code does not match the geography master data
+	ErrCodeClubLeader           = "INVALID_CLUB_LEADER"    // 422, clubLeaderFarmerId is not the club's leader or not a farmer of the cooperative
+	ErrCodeFarmerNotFound       = "FARMER_NOT_FOUND"       // 404
+	ErrCodeClubNotFound         = "CLUB_NOT_FOUND"         // 404
+	ErrCodeGeographyNotFound    = "GEOGRAPHY_NOT_FOUND"    // 404, unknown geography level or ID
+	ErrCodeNotFound             = "NOT_FOUND"              // 404, no such route
+	ErrCodeDuplicateFarmer      = "DUPLICATE_FARMER"       // 409, farmerId already registered in the cooperative
+	ErrCodeDuplicateKYC         = "DUPLICATE_KYC"          // 409, farmer_kyc_id belongs to another farmer
+	ErrCodeDetailsMismatch      = "DETAILS_MISMATCH"       // 409, a role was added with details that differ from the stored farmer
+	ErrCodeDuplicateCoop        = "DUPLICATE_COOP"         // 409, admin API only
+	ErrCodeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED" // 409, the Idempotency-Key was sent before with another body
+	ErrCodeStorage              = "STORAGE_ERROR"          // 502, the database failed
+	ErrCodeUpstream             = "UPSTREAM_ERROR"         // 502, the proxied ERP could not be reached
+	ErrCodeInternal             = "INTERNAL_ERROR"         // 500
+	ErrCodeInjectedFault        = "INJECTED_FAULT"         // any status, a failure simulated by the fault injector
+	ErrCodeFaultNotFound        = "FAULT_NOT_FOUND"        // 404, no fault rule with that ID
+)
+
+// BulkFarmerResponse holds one result per submitted farmer, in request order
+type BulkFarmerResponse struct {
+	Success   bool               `json:"success"`
+	Mode      string             `json:"mode"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Results   []BulkFarmerResult `json:"results"`
+}
+
+// BulkFarmerResult carries data for a registered item, or error with the
+// same code and message a single create would have returned
+type BulkFarmerResult struct {
+	Success bool                 `json:"success"`
+	Data    *FarmerResponse      `json:"data,omitempty"`
+	Error   *ErrorFarmerResponse `json:"error,omitempty"`
 }
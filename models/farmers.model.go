@@ -1,6 +1,10 @@
 package models
 
 import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -10,42 +14,138 @@ import (
 
 // Detail represents the 'details' table in the database
 type FarmerDetails struct {
-	ID                          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
-	TempID                      string     `gorm:"not null" json:"tempId"`
-	CoopID                      string     `gorm:"not null" json:"coopId"`
-	CustomerID                  string     `json:"customerId"`
-	VendorID                    string     `json:"vendorId"`
-	FarmerID                    string     `gorm:"not null" json:"farmerId"`
-	FirstName                   string     `gorm:"not null" json:"firstName"`
-	LastName                    string     `gorm:"not null" json:"lastName"`
-	MobileNumber                string     `json:"mobile_number"`
-	RegionID                    int        `json:"regionId"`
-	RegionPartID                int        `json:"regionPartId"`
-	SettlementID                int        `json:"settlementId"`
-	SettlementPartID            int        `json:"settlementPartId"`
-	CustomGeographyStructure1ID string     `json:"custom_geography_structure1_id"`
-	CustomGeographyStructure2ID string     `json:"custom_geography_structure2_id"`
-	ZipCode                     string     `json:"zipCode"`
-	FarmerKycTypeID             int        `json:"farmer_kyc_type_id"`
-	FarmerKycType               string     `json:"farmer_kyc_type"`
-	FarmerKycID                 string     `json:"farmer_kyc_id"`
-	ClubID                      string     `json:"clubId"`
-	ClubName                    string     `json:"clubName"`
-	ClubLeaderFarmerID          string     `json:"clubLeaderFarmerId" `
-	RaithuCreatedDate           *time.Time `json:"raithuCreatedDate" gorm:"default:null"`
-	RaithuUpdatedAt             *time.Time `json:"raithuUpdatedAt" gorm:"default:null"`
-	CreatedAt                   *time.Time `gorm:"default:null" `
-	UpdatedAt                   *time.Time `gorm:"default:null"`
-	CustIDUpdateAt              *time.Time `gorm:"default:null"`
-	VendorIDUpdateAt            *time.Time `gorm:"default:null"`
+	ID                          uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	TempID                      string         `gorm:"not null" json:"tempId"`
+	CoopID                      string         `gorm:"not null" json:"coopId"`
+	CustomerID                  string         `json:"customerId"`
+	VendorID                    string         `json:"vendorId"`
+	FarmerID                    string         `gorm:"not null" json:"farmerId"`
+	FirstName                   string         `gorm:"not null" json:"firstName"`
+	LastName                    string         `gorm:"not null" json:"lastName"`
+	MobileNumber                string         `json:"mobile_number"`
+	RegionID                    int            `json:"regionId"`
+	RegionPartID                int            `json:"regionPartId"`
+	SettlementID                int            `json:"settlementId"`
+	SettlementPartID            int            `json:"settlementPartId"`
+	CustomGeographyStructure1ID string         `json:"custom_geography_structure1_id"`
+	CustomGeographyStructure2ID string         `json:"custom_geography_structure2_id"`
+	ZipCode                     string         `json:"zipCode"`
+	FarmerKycTypeID             int            `json:"farmer_kyc_type_id"`
+	FarmerKycType               string         `json:"farmer_kyc_type"`
+	FarmerKycID                 string         `json:"farmer_kyc_id"`
+	ClubID                      string         `json:"clubId"`
+	ClubName                    string         `json:"clubName"`
+	ClubLeaderFarmerID          string         `json:"clubLeaderFarmerId" `
+	RaithuCreatedDate           *time.Time     `json:"raithuCreatedDate" gorm:"default:null"`
+	RaithuUpdatedAt             *time.Time     `json:"raithuUpdatedAt" gorm:"default:null"`
+	CreatedAt                   *time.Time     `gorm:"default:null" `
+	UpdatedAt                   *time.Time     `gorm:"default:null"`
+	CustIDUpdateAt              *time.Time     `gorm:"default:null"`
+	VendorIDUpdateAt            *time.Time     `gorm:"default:null"`
+	CustomerStatus              string         `gorm:"index" json:"customerStatus"`
+	VendorStatus                string         `gorm:"index" json:"vendorStatus"`
+	TempVendorID                string         `json:"tempVendorId"`
+	CustomerRegisteredAt        *time.Time     `gorm:"default:null"`
+	VendorRegisteredAt          *time.Time     `gorm:"default:null"`
+	DeletedAt                   gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+
+	// Unique keys backing the duplicate checks in the database. They are
+	// NULL for soft-deleted farmers and empty KYC IDs, so those never collide.
+	FarmerKey *string `gorm:"uniqueIndex;size:191" json:"-"` // coop_id/farmer_id
+	KycKey    *string `gorm:"uniqueIndex;size:191" json:"-"` // farmer_kyc_id
+}
+
+// Registration states of a customer or vendor role. An empty status means the
+// farmer was never registered in that role.
+const (
+	RegistrationPending  = "PENDING"
+	RegistrationApproved = "APPROVED"
+)
+
+// Roles a farmer can be registered in with the ERP
+const (
+	RoleCustomer = "customer"
+	RoleVendor   = "vendor"
+)
+
+// HasRole reports whether the farmer was registered as a customer or vendor
+func (d *FarmerDetails) HasRole(role string) bool {
+	return d.RoleStatus(role) != ""
+}
+
+// RoleStatus returns the registration status of the given role
+func (d *FarmerDetails) RoleStatus(role string) string {
+	switch role {
+	case RoleCustomer:
+		return d.CustomerStatus
+	case RoleVendor:
+		return d.VendorStatus
+	}
+	return ""
+}
+
+// RoleTempID returns the temporary ERP ID issued when the role was registered
+func (d *FarmerDetails) RoleTempID(role string) string {
+	if role == RoleVendor {
+		return d.TempVendorID
+	}
+	return d.TempID
+}
+
+// RoleRegisteredAt returns when the role was registered. Rows created before
+// roles were tracked separately fall back to CreatedAt.
+func (d *FarmerDetails) RoleRegisteredAt(role string) *time.Time {
+	registeredAt := d.CustomerRegisteredAt
+	if role == RoleVendor {
+		registeredAt = d.VendorRegisteredAt
+	}
+	if registeredAt == nil {
+		return d.CreatedAt
+	}
+	return registeredAt
+}
+
+// AddRole registers the farmer as a customer or vendor, issuing a new temp ID
+// that stays valid until the ERP approves the role
+func (d *FarmerDetails) AddRole(role string, now time.Time) {
+	tempID := uuid.New().String()
+	switch role {
+	case RoleCustomer:
+		d.TempID = tempID
+		d.CustomerStatus = RegistrationPending
+		d.CustomerRegisteredAt = &now
+	case RoleVendor:
+		d.TempVendorID = tempID
+		d.VendorStatus = RegistrationPending
+		d.VendorRegisteredAt = &now
+	}
+	d.UpdatedAt = &now
+}
+
+// SetUniqueKeys derives FarmerKey and KycKey from the record
+func (d *FarmerDetails) SetUniqueKeys() {
+	d.FarmerKey, d.KycKey = nil, nil
+	if d.DeletedAt.Valid {
+		return
+	}
+
+	farmerKey := d.CoopID + "/" + d.FarmerID
+	d.FarmerKey = &farmerKey
+	if d.FarmerKycID != "" {
+		kycKey := d.FarmerKycID
+		d.KycKey = &kycKey
+	}
+}
+
+// BeforeSave Hook keeps the unique keys in step on every create and update
+func (d *FarmerDetails) BeforeSave(tx *gorm.DB) (err error) {
+	d.SetUniqueKeys()
+	return nil
 }
 
 // BeforeCreate Hook to handle any logic before saving to DB
 func (d *FarmerDetails) BeforeCreate(tx *gorm.DB) (err error) {
 	now := time.Now().UTC()
-	if d.TempID == "" {
-		d.TempID = uuid.New().String()
-	}
 
 	d.CreatedAt = &now
 	d.UpdatedAt = &now
@@ -53,58 +153,133 @@ func (d *FarmerDetails) BeforeCreate(tx *gorm.DB) (err error) {
 }
 
 // Initialize the validator once for the package
-var validate = validator.New()
+var validate = newValidator()
+
+var (
+	mobileNumberPattern = regexp.MustCompile(`^(\+91)?[6-9][0-9]{9}$`)
+	zipCodePattern      = regexp.MustCompile(`^[1-9][0-9]{5}$`)
+)
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	// Report fields by their JSON name, the way the client sent them
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.RegisterValidation("mobile", func(fl validator.FieldLevel) bool {
+		return mobileNumberPattern.MatchString(fl.Field().String())
+	})
+	v.RegisterValidation("zipcode", func(fl validator.FieldLevel) bool {
+		return zipCodePattern.MatchString(fl.Field().String())
+	})
+	return v
+}
 
 // ErrorResponse defines the structure for API validation error messages
 type ErrorResponse struct {
-	Field string `json:"field"`
-	Tag   string `json:"tag"`
-	Value string `json:"value,omitempty"`
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
 }
 
+// InvalidPayloadTag marks the ErrorResponse of a payload that could not be
+// validated at all, such as a JSON null
+const InvalidPayloadTag = "payload"
+
 // ValidateStruct is a generic function that validates any struct against 'validate' tags
 // It returns a slice of ErrorResponse pointers if validation fails
-// func ValidateStruct[T any](payload T) []*ErrorResponse {
-// 	var errors []*ErrorResponse
+func ValidateStruct[T any](payload T) []*ErrorResponse {
+	var errs []*ErrorResponse
+
+	// Execute validation
+	err := validate.Struct(payload)
 
-// 	// Execute validation
-// 	err := validate.Struct(payload)
+	var fieldErrors validator.ValidationErrors
+	if err != nil && !errors.As(err, &fieldErrors) {
+		return []*ErrorResponse{{Tag: InvalidPayloadTag, Message: "the request body must be a JSON object"}}
+	}
+	if err != nil {
+		// Cast the error to validator.ValidationErrors to access individual field errors
+		for _, err := range fieldErrors {
+			var element ErrorResponse
+			element.Field = err.Field() // e.g., "firstName"
+			element.Tag = err.Tag()     // e.g., "required"
+			element.Value = err.Param() // e.g., "32" (for min=32)
+			element.Message = validationMessage(err)
 
-// 	if err != nil {
-// 		// Cast the error to validator.ValidationErrors to access individual field errors
-// 		for _, err := range err.(validator.ValidationErrors) {
-// 			var element ErrorResponse
-// 			element.Field = err.StructNamespace() // e.g., "CreateDetailSchema.FirstName"
-// 			element.Tag = err.Tag()               // e.g., "required"
-// 			element.Value = err.Param()           // e.g., "32" (for min=32)
+			errs = append(errs, &element)
+		}
+	}
 
-// 			errors = append(errors, &element)
-// 		}
-// 	}
+	return errs
+}
 
-// 	return errors
-// }
+func validationMessage(err validator.FieldError) string {
+	switch err.Tag() {
+	case "required":
+		return err.Field() + " is required"
+	case "required_if":
+		field, value, _ := strings.Cut(err.Param(), " ")
+		return err.Field() + " is required when " + paramFieldName(field) + " is " + value
+	case "required_unless":
+		field, value, _ := strings.Cut(err.Param(), " ")
+		return err.Field() + " is required unless " + paramFieldName(field) + " is " + value
+	case "required_without":
+		return "Either farmer_kyc_id or clubLeaderFarmerId must be provided"
+	case "mobile":
+		return err.Field() + " must be a 10 digit Indian mobile number, optionally prefixed with +91"
+	case "zipcode":
+		return err.Field() + " must be a 6 digit PIN code"
+	case "oneof":
+		return err.Field() + " must be one of: " + err.Param()
+	case "datetime":
+		return err.Field() + " must be an RFC 3339 date-time, e.g. 2025-12-30T05:03:17.863Z"
+	case "min", "gte":
+		return err.Field() + " must be at least " + err.Param()
+	case "max", "lte":
+		return err.Field() + " must be at most " + err.Param()
+	case "gtefield":
+		return err.Field() + " must not be less than " + paramFieldName(err.Param())
+	}
+	return err.Field() + " failed the " + err.Tag() + " rule"
+}
+
+// paramFieldName turns the Go field name in a rule param into its camelCase
+// JSON name
+func paramFieldName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
 
 // CreateDetailSchema represents request body
 // swagger:model CreateDetailSchema
 type CreateDetailSchema struct {
-	FarmerID           string `json:"farmerId" example:"string"`
-	FirstName          string `json:"firstName" example:"string"`
-	LastName           string `json:"lastName" example:"string"`
-	MobileNumber       string `json:"mobile_number" example:"string"`
-	RegionID           int    `json:"regionId" example:"0"`
-	RegionPartID       int    `json:"regionPartID" example:"0"`
-	SettlementID       int    `json:"settlementID" example:"0"`
-	SettlementPartID   int    `json:"settlementPartID" example:"0"`
+	FarmerID           string `json:"farmerId" example:"string" validate:"required"`
+	FirstName          string `json:"firstName" example:"string" validate:"required"`
+	LastName           string `json:"lastName" example:"string" validate:"required"`
+	MobileNumber       string `json:"mobile_number" example:"9876543210" validate:"omitempty,mobile"`
+	RegionID           int    `json:"regionId" example:"0" validate:"gte=0"`
+	RegionPartID       int    `json:"regionPartID" example:"0" validate:"gte=0"`
+	SettlementID       int    `json:"settlementID" example:"0" validate:"gte=0"`
+	SettlementPartID   int    `json:"settlementPartID" example:"0" validate:"gte=0"`
 	CustomGeo1ID       string `json:"custom_geography_structure1_id" example:"0"`
 	CustomGeo2ID       string `json:"custom_geography_structure2_id" example:"0"`
-	ZipCode            string `json:"ZipCode" example:"string"`
-	FarmerKycTypeID    int    `json:"farmer_kyc_type_id" example:"0"`
-	FarmerKycType      string `json:"farmer_kyc_type" example:"string"`
-	FarmerKycID        string `json:"farmer_kyc_id" example:"string"`
+	ZipCode            string `json:"ZipCode" example:"500001" validate:"omitempty,zipcode"`
+	FarmerKycTypeID    int    `json:"farmer_kyc_type_id" example:"1" validate:"gte=0"`
+	FarmerKycType      string `json:"farmer_kyc_type" example:"AADHAAR"`
+	FarmerKycID        string `json:"farmer_kyc_id" example:"234567890123" validate:"required_without=ClubLeaderFarmerID"`
 	ClubID             string `json:"clubId" example:"string"`
 	ClubName           string `json:"clubName" example:"string"`
 	ClubLeaderFarmerID string `json:"clubLeaderFarmerId" example:"string"`
-	RaithuCreatedDate  string `json:"raithuCreatedDate" example:"2025-12-30T05:03:17.863Z"`
-	RaithuUpdatedAt    string `json:"raithuUpdatedAt" example:"2025-12-30T05:03:17.863Z"`
+	RaithuCreatedDate  string `json:"raithuCreatedDate" example:"2025-12-30T05:03:17.863Z" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	RaithuUpdatedAt    string `json:"raithuUpdatedAt" example:"2025-12-30T05:03:17.863Z" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
 }
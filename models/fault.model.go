@@ -0,0 +1,48 @@
+package models
+
+import "encoding/json"
+
+// Kinds of failure the fault injector can simulate
+const (
+	FaultStatus    = "status"    // answer Status with an INJECTED_FAULT error body
+	FaultMalformed = "malformed" // answer 200 with truncated JSON
+	FaultReset     = "reset"     // drop the connection without a response
+	FaultTimeout   = "timeout"   // hold the request for DelayMs, then answer 504
+)
+
+// FaultRule makes a share of the matching requests fail. Empty Method,
+// Route and CoopID match everything; Route may use * as a wildcard, e.g.
+// /spic_to_erp/*/COOP001/farmers*.
+// swagger:model FaultRule
+type FaultRule struct {
+	ID      string  `json:"id" example:"f1"`
+	Method  string  `json:"method,omitempty" example:"POST"`
+	Route   string  `json:"route,omitempty" example:"/spic_to_erp/customers/*/farmers"`
+	CoopID  string  `json:"coopId,omitempty" example:"COOP001"`
+	Percent float64 `json:"percent" example:"25" default:"100" validate:"gte=0,lte=100"`
+	Type    string  `json:"type" example:"status" validate:"required,oneof=status malformed reset timeout"`
+	Status  int     `json:"status,omitempty" example:"503" validate:"required_if=Type status,omitempty,min=400,max=599"`
+	DelayMs int     `json:"delayMs,omitempty" example:"30000" validate:"gte=0"`
+}
+
+// UnmarshalJSON defaults Percent to 100, as a rule that never fires is
+// almost never what an omitted percent means
+func (r *FaultRule) UnmarshalJSON(data []byte) error {
+	type plain FaultRule
+	rule := plain{Percent: 100}
+	if err := json.Unmarshal(data, &rule); err != nil {
+		return err
+	}
+	*r = FaultRule(rule)
+	return nil
+}
+
+type FaultRuleResponse struct {
+	Success bool      `json:"success"`
+	Data    FaultRule `json:"data"`
+}
+
+type ListFaultRulesResponse struct {
+	Success bool        `json:"success"`
+	Data    []FaultRule `json:"data"`
+}
@@ -0,0 +1,44 @@
+package models
+
+// Levels of the geography hierarchy. A region is split into region parts,
+// region parts into settlements and settlements into settlement parts. The
+// two custom structures form a separate two-level hierarchy.
+const (
+	GeoRegion         = "region"
+	GeoRegionPart     = "region-part"
+	GeoSettlement     = "settlement"
+	GeoSettlementPart = "settlement-part"
+	GeoCustom1        = "custom-1"
+	GeoCustom2        = "custom-2"
+)
+
+// GeographyParentLevel gives the level a node's parentId refers to
+var GeographyParentLevel = map[string]string{
+	GeoRegion:         "",
+	GeoRegionPart:     GeoRegion,
+	GeoSettlement:     GeoRegionPart,
+	GeoSettlementPart: GeoSettlement,
+	GeoCustom1:        "",
+	GeoCustom2:        GeoCustom1,
+}
+
+// GeographyNode is one entry of the geography master data, and one row of
+// the CSV or JSON file it is loaded from
+type GeographyNode struct {
+	Type     string `json:"type" example:"settlement"`
+	ID       string `json:"id" example:"1101"`
+	ParentID string `json:"parentId,omitempty" example:"110"`
+	Name     string `json:"name" example:"Tenali"`
+	ZipCode  string `json:"zipCode,omitempty" example:"522201"`
+}
+
+type GeographyListResponse struct {
+	Success bool            `json:"success"`
+	Data    []GeographyNode `json:"data"`
+}
+
+type GeographyNodeResponse struct {
+	Success  bool            `json:"success"`
+	Data     GeographyNode   `json:"data"`
+	Children []GeographyNode `json:"children"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// KycType is an entry of the ERP's KYC document catalogue. Pattern is the
+// regular expression a farmer_kyc_id of this type must match.
+type KycType struct {
+	ID      int    `json:"id" example:"1"`
+	Name    string `json:"name" example:"AADHAAR"`
+	Pattern string `json:"pattern" example:"^[2-9][0-9]{11}$"`
+}
+
+type ListKycTypesResponse struct {
+	Success bool      `json:"success"`
+	Data    []KycType `json:"data"`
+}
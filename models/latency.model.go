@@ -0,0 +1,36 @@
+package models
+
+// Delay distributions of a latency rule
+const (
+	LatencyFixed   = "fixed"   // always DelayMs
+	LatencyUniform = "uniform" // anywhere between MinMs and MaxMs
+	LatencyNormal  = "normal"  // around MeanMs with StdDevMs, never below 0
+)
+
+// LatencyRule delays the matching requests. Empty Method and Route match
+// everything; Route may use * as a wildcard.
+// swagger:model LatencyRule
+type LatencyRule struct {
+	ID           string `json:"id,omitempty" example:"create"`
+	Method       string `json:"method,omitempty" example:"POST"`
+	Route        string `json:"route,omitempty" example:"/spic_to_erp/*/*/farmers"`
+	Distribution string `json:"distribution" example:"normal" validate:"required,oneof=fixed uniform normal"`
+	DelayMs      int    `json:"delayMs,omitempty" example:"800" validate:"gte=0"`
+	MinMs        int    `json:"minMs,omitempty" example:"200" validate:"gte=0"`
+	MaxMs        int    `json:"maxMs,omitempty" example:"1500" validate:"gte=0,gtefield=MinMs"`
+	MeanMs       int    `json:"meanMs,omitempty" example:"900" validate:"gte=0"`
+	StdDevMs     int    `json:"stdDevMs,omitempty" example:"250" validate:"gte=0"`
+}
+
+// LatencySettings are the rules in the order they are evaluated, and
+// whether they apply at all
+// swagger:model LatencySettings
+type LatencySettings struct {
+	Enabled bool          `json:"enabled"`
+	Rules   []LatencyRule `json:"rules" validate:"dive"`
+}
+
+type LatencySettingsResponse struct {
+	Success bool            `json:"success"`
+	Data    LatencySettings `json:"data"`
+}
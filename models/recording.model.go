@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Recording is one request to the upstream ERP and its response, stored as
+// a line of the recordings JSONL file
+type Recording struct {
+	RecordedAt   time.Time         `json:"recordedAt"`
+	Method       string            `json:"method"`
+	Path         string            `json:"path"` // with the query string
+	RequestBody  string            `json:"requestBody,omitempty"`
+	Status       int               `json:"status"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	ResponseBody string            `json:"responseBody,omitempty"`
+	DurationMs   int64             `json:"durationMs"`
+}
@@ -0,0 +1,53 @@
+package models
+
+// What a scenario does once every response of its sequence has been served
+const (
+	ScenarioPassthrough = "passthrough" // stop matching, the normal handlers answer
+	ScenarioRepeat      = "repeat"      // keep serving the last response
+	ScenarioLoop        = "loop"        // start the sequence over
+)
+
+// Scenario serves scripted responses, in sequence, to the requests it
+// matches. Empty matchers match everything; Route may use * as a wildcard
+// and Body compares fields of the JSON body, dotted paths reaching into
+// nested objects.
+// swagger:model Scenario
+type Scenario struct {
+	ID        string             `json:"id" yaml:"id" example:"retry-after-502"`
+	Method    string             `json:"method,omitempty" yaml:"method" example:"POST"`
+	Route     string             `json:"route,omitempty" yaml:"route" example:"/spic_to_erp/customers/*/farmers"`
+	CoopID    string             `json:"coopId,omitempty" yaml:"coopId" example:"COOP001"`
+	FarmerID  string             `json:"farmerId,omitempty" yaml:"farmerId" example:"F100"`
+	Body      map[string]any     `json:"body,omitempty" yaml:"body"`
+	Responses []ScenarioResponse `json:"responses" yaml:"responses" validate:"required,min=1,dive"`
+	AfterLast string             `json:"afterLast,omitempty" yaml:"afterLast" example:"passthrough" validate:"omitempty,oneof=passthrough repeat loop"`
+}
+
+// ScenarioResponse is one step of a scenario. Passthrough lets the normal
+// handlers answer the step; otherwise Body is sent as JSON, or an error
+// body built from Code and Message when Body is empty.
+type ScenarioResponse struct {
+	Passthrough bool              `json:"passthrough,omitempty" yaml:"passthrough"`
+	Status      int               `json:"status,omitempty" yaml:"status" example:"502" validate:"required_unless=Passthrough true,omitempty,min=100,max=599"`
+	Code        string            `json:"code,omitempty" yaml:"code" example:"STORAGE_ERROR"`
+	Message     string            `json:"message,omitempty" yaml:"message" example:"ERP unavailable"`
+	Body        any               `json:"body,omitempty" yaml:"body" swaggertype:"object"`
+	Headers     map[string]string `json:"headers,omitempty" yaml:"headers"`
+	DelayMs     int               `json:"delayMs,omitempty" yaml:"delayMs" validate:"gte=0"`
+}
+
+// ScenarioState is a scenario and how many of its responses were served
+type ScenarioState struct {
+	Scenario
+	Served int `json:"served"`
+}
+
+type ScenarioResponseBody struct {
+	Success bool          `json:"success"`
+	Data    ScenarioState `json:"data"`
+}
+
+type ListScenariosResponse struct {
+	Success bool            `json:"success"`
+	Data    []ScenarioState `json:"data"`
+}
@@ -0,0 +1,523 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+func newFarmerDetails(db *gorm.DB, opts ...gen.DOOption) farmerDetails {
+	_farmerDetails := farmerDetails{}
+
+	_farmerDetails.farmerDetailsDo.UseDB(db, opts...)
+	_farmerDetails.farmerDetailsDo.UseModel(&models.FarmerDetails{})
+
+	tableName := _farmerDetails.farmerDetailsDo.TableName()
+	_farmerDetails.ALL = field.NewAsterisk(tableName)
+	_farmerDetails.ID = field.NewUint(tableName, "id")
+	_farmerDetails.TempID = field.NewString(tableName, "temp_id")
+	_farmerDetails.CoopID = field.NewString(tableName, "coop_id")
+	_farmerDetails.CustomerID = field.NewString(tableName, "customer_id")
+	_farmerDetails.VendorID = field.NewString(tableName, "vendor_id")
+	_farmerDetails.FarmerID = field.NewString(tableName, "farmer_id")
+	_farmerDetails.FirstName = field.NewString(tableName, "first_name")
+	_farmerDetails.LastName = field.NewString(tableName, "last_name")
+	_farmerDetails.MobileNumber = field.NewString(tableName, "mobile_number")
+	_farmerDetails.RegionID = field.NewInt(tableName, "region_id")
+	_farmerDetails.RegionPartID = field.NewInt(tableName, "region_part_id")
+	_farmerDetails.SettlementID = field.NewInt(tableName, "settlement_id")
+	_farmerDetails.SettlementPartID = field.NewInt(tableName, "settlement_part_id")
+	_farmerDetails.CustomGeographyStructure1ID = field.NewString(tableName, "custom_geography_structure1_id")
+	_farmerDetails.CustomGeographyStructure2ID = field.NewString(tableName, "custom_geography_structure2_id")
+	_farmerDetails.ZipCode = field.NewString(tableName, "zip_code")
+	_farmerDetails.FarmerKycTypeID = field.NewInt(tableName, "farmer_kyc_type_id")
+	_farmerDetails.FarmerKycType = field.NewString(tableName, "farmer_kyc_type")
+	_farmerDetails.FarmerKycID = field.NewString(tableName, "farmer_kyc_id")
+	_farmerDetails.ClubID = field.NewString(tableName, "club_id")
+	_farmerDetails.ClubName = field.NewString(tableName, "club_name")
+	_farmerDetails.ClubLeaderFarmerID = field.NewString(tableName, "club_leader_farmer_id")
+	_farmerDetails.RaithuCreatedDate = field.NewTime(tableName, "raithu_created_date")
+	_farmerDetails.RaithuUpdatedAt = field.NewTime(tableName, "raithu_updated_at")
+	_farmerDetails.CreatedAt = field.NewTime(tableName, "created_at")
+	_farmerDetails.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_farmerDetails.CustIDUpdateAt = field.NewTime(tableName, "cust_id_update_at")
+	_farmerDetails.VendorIDUpdateAt = field.NewTime(tableName, "vendor_id_update_at")
+	_farmerDetails.CustomerStatus = field.NewString(tableName, "customer_status")
+	_farmerDetails.VendorStatus = field.NewString(tableName, "vendor_status")
+	_farmerDetails.TempVendorID = field.NewString(tableName, "temp_vendor_id")
+	_farmerDetails.CustomerRegisteredAt = field.NewTime(tableName, "customer_registered_at")
+	_farmerDetails.VendorRegisteredAt = field.NewTime(tableName, "vendor_registered_at")
+	_farmerDetails.DeletedAt = field.NewField(tableName, "deleted_at")
+	_farmerDetails.FarmerKey = field.NewString(tableName, "farmer_key")
+	_farmerDetails.KycKey = field.NewString(tableName, "kyc_key")
+
+	_farmerDetails.fillFieldMap()
+
+	return _farmerDetails
+}
+
+type farmerDetails struct {
+	farmerDetailsDo
+
+	ALL                         field.Asterisk
+	ID                          field.Uint
+	TempID                      field.String
+	CoopID                      field.String
+	CustomerID                  field.String
+	VendorID                    field.String
+	FarmerID                    field.String
+	FirstName                   field.String
+	LastName                    field.String
+	MobileNumber                field.String
+	RegionID                    field.Int
+	RegionPartID                field.Int
+	SettlementID                field.Int
+	SettlementPartID            field.Int
+	CustomGeographyStructure1ID field.String
+	CustomGeographyStructure2ID field.String
+	ZipCode                     field.String
+	FarmerKycTypeID             field.Int
+	FarmerKycType               field.String
+	FarmerKycID                 field.String
+	ClubID                      field.String
+	ClubName                    field.String
+	ClubLeaderFarmerID          field.String
+	RaithuCreatedDate           field.Time
+	RaithuUpdatedAt             field.Time
+	CreatedAt                   field.Time
+	UpdatedAt                   field.Time
+	CustIDUpdateAt              field.Time
+	VendorIDUpdateAt            field.Time
+	CustomerStatus              field.String
+	VendorStatus                field.String
+	TempVendorID                field.String
+	CustomerRegisteredAt        field.Time
+	VendorRegisteredAt          field.Time
+	DeletedAt                   field.Field
+	FarmerKey                   field.String
+	KycKey                      field.String
+
+	fieldMap map[string]field.Expr
+}
+
+func (f farmerDetails) Table(newTableName string) *farmerDetails {
+	f.farmerDetailsDo.UseTable(newTableName)
+	return f.updateTableName(newTableName)
+}
+
+func (f farmerDetails) As(alias string) *farmerDetails {
+	f.farmerDetailsDo.DO = *(f.farmerDetailsDo.As(alias).(*gen.DO))
+	return f.updateTableName(alias)
+}
+
+func (f *farmerDetails) updateTableName(table string) *farmerDetails {
+	f.ALL = field.NewAsterisk(table)
+	f.ID = field.NewUint(table, "id")
+	f.TempID = field.NewString(table, "temp_id")
+	f.CoopID = field.NewString(table, "coop_id")
+	f.CustomerID = field.NewString(table, "customer_id")
+	f.VendorID = field.NewString(table, "vendor_id")
+	f.FarmerID = field.NewString(table, "farmer_id")
+	f.FirstName = field.NewString(table, "first_name")
+	f.LastName = field.NewString(table, "last_name")
+	f.MobileNumber = field.NewString(table, "mobile_number")
+	f.RegionID = field.NewInt(table, "region_id")
+	f.RegionPartID = field.NewInt(table, "region_part_id")
+	f.SettlementID = field.NewInt(table, "settlement_id")
+	f.SettlementPartID = field.NewInt(table, "settlement_part_id")
+	f.CustomGeographyStructure1ID = field.NewString(table, "custom_geography_structure1_id")
+	f.CustomGeographyStructure2ID = field.NewString(table, "custom_geography_structure2_id")
+	f.ZipCode = field.NewString(table, "zip_code")
+	f.FarmerKycTypeID = field.NewInt(table, "farmer_kyc_type_id")
+	f.FarmerKycType = field.NewString(table, "farmer_kyc_type")
+	f.FarmerKycID = field.NewString(table, "farmer_kyc_id")
+	f.ClubID = field.NewString(table, "club_id")
+	f.ClubName = field.NewString(table, "club_name")
+	f.ClubLeaderFarmerID = field.NewString(table, "club_leader_farmer_id")
+	f.RaithuCreatedDate = field.NewTime(table, "raithu_created_date")
+	f.RaithuUpdatedAt = field.NewTime(table, "raithu_updated_at")
+	f.CreatedAt = field.NewTime(table, "created_at")
+	f.UpdatedAt = field.NewTime(table, "updated_at")
+	f.CustIDUpdateAt = field.NewTime(table, "cust_id_update_at")
+	f.VendorIDUpdateAt = field.NewTime(table, "vendor_id_update_at")
+	f.CustomerStatus = field.NewString(table, "customer_status")
+	f.VendorStatus = field.NewString(table, "vendor_status")
+	f.TempVendorID = field.NewString(table, "temp_vendor_id")
+	f.CustomerRegisteredAt = field.NewTime(table, "customer_registered_at")
+	f.VendorRegisteredAt = field.NewTime(table, "vendor_registered_at")
+	f.DeletedAt = field.NewField(table, "deleted_at")
+	f.FarmerKey = field.NewString(table, "farmer_key")
+	f.KycKey = field.NewString(table, "kyc_key")
+
+	f.fillFieldMap()
+
+	return f
+}
+
+func (f *farmerDetails) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := f.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (f *farmerDetails) fillFieldMap() {
+	f.fieldMap = make(map[string]field.Expr, 36)
+	f.fieldMap["id"] = f.ID
+	f.fieldMap["temp_id"] = f.TempID
+	f.fieldMap["coop_id"] = f.CoopID
+	f.fieldMap["customer_id"] = f.CustomerID
+	f.fieldMap["vendor_id"] = f.VendorID
+	f.fieldMap["farmer_id"] = f.FarmerID
+	f.fieldMap["first_name"] = f.FirstName
+	f.fieldMap["last_name"] = f.LastName
+	f.fieldMap["mobile_number"] = f.MobileNumber
+	f.fieldMap["region_id"] = f.RegionID
+	f.fieldMap["region_part_id"] = f.RegionPartID
+	f.fieldMap["settlement_id"] = f.SettlementID
+	f.fieldMap["settlement_part_id"] = f.SettlementPartID
+	f.fieldMap["custom_geography_structure1_id"] = f.CustomGeographyStructure1ID
+	f.fieldMap["custom_geography_structure2_id"] = f.CustomGeographyStructure2ID
+	f.fieldMap["zip_code"] = f.ZipCode
+	f.fieldMap["farmer_kyc_type_id"] = f.FarmerKycTypeID
+	f.fieldMap["farmer_kyc_type"] = f.FarmerKycType
+	f.fieldMap["farmer_kyc_id"] = f.FarmerKycID
+	f.fieldMap["club_id"] = f.ClubID
+	f.fieldMap["club_name"] = f.ClubName
+	f.fieldMap["club_leader_farmer_id"] = f.ClubLeaderFarmerID
+	f.fieldMap["raithu_created_date"] = f.RaithuCreatedDate
+	f.fieldMap["raithu_updated_at"] = f.RaithuUpdatedAt
+	f.fieldMap["created_at"] = f.CreatedAt
+	f.fieldMap["updated_at"] = f.UpdatedAt
+	f.fieldMap["cust_id_update_at"] = f.CustIDUpdateAt
+	f.fieldMap["vendor_id_update_at"] = f.VendorIDUpdateAt
+	f.fieldMap["customer_status"] = f.CustomerStatus
+	f.fieldMap["vendor_status"] = f.VendorStatus
+	f.fieldMap["temp_vendor_id"] = f.TempVendorID
+	f.fieldMap["customer_registered_at"] = f.CustomerRegisteredAt
+	f.fieldMap["vendor_registered_at"] = f.VendorRegisteredAt
+	f.fieldMap["deleted_at"] = f.DeletedAt
+	f.fieldMap["farmer_key"] = f.FarmerKey
+	f.fieldMap["kyc_key"] = f.KycKey
+}
+
+func (f farmerDetails) clone(db *gorm.DB) farmerDetails {
+	f.farmerDetailsDo.ReplaceConnPool(db.Statement.ConnPool)
+	return f
+}
+
+func (f farmerDetails) replaceDB(db *gorm.DB) farmerDetails {
+	f.farmerDetailsDo.ReplaceDB(db)
+	return f
+}
+
+type farmerDetailsDo struct{ gen.DO }
+
+type IFarmerDetailsDo interface {
+	gen.SubQuery
+	Debug() IFarmerDetailsDo
+	WithContext(ctx context.Context) IFarmerDetailsDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() IFarmerDetailsDo
+	WriteDB() IFarmerDetailsDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) IFarmerDetailsDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) IFarmerDetailsDo
+	Not(conds ...gen.Condition) IFarmerDetailsDo
+	Or(conds ...gen.Condition) IFarmerDetailsDo
+	Select(conds ...field.Expr) IFarmerDetailsDo
+	Where(conds ...gen.Condition) IFarmerDetailsDo
+	Order(conds ...field.Expr) IFarmerDetailsDo
+	Distinct(cols ...field.Expr) IFarmerDetailsDo
+	Omit(cols ...field.Expr) IFarmerDetailsDo
+	Join(table schema.Tabler, on ...field.Expr) IFarmerDetailsDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) IFarmerDetailsDo
+	RightJoin(table schema.Tabler, on ...field.Expr) IFarmerDetailsDo
+	Group(cols ...field.Expr) IFarmerDetailsDo
+	Having(conds ...gen.Condition) IFarmerDetailsDo
+	Limit(limit int) IFarmerDetailsDo
+	Offset(offset int) IFarmerDetailsDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) IFarmerDetailsDo
+	Unscoped() IFarmerDetailsDo
+	Create(values ...*models.FarmerDetails) error
+	CreateInBatches(values []*models.FarmerDetails, batchSize int) error
+	Save(values ...*models.FarmerDetails) error
+	First() (*models.FarmerDetails, error)
+	Take() (*models.FarmerDetails, error)
+	Last() (*models.FarmerDetails, error)
+	Find() ([]*models.FarmerDetails, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*models.FarmerDetails, err error)
+	FindInBatches(result *[]*models.FarmerDetails, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*models.FarmerDetails) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) IFarmerDetailsDo
+	Assign(attrs ...field.AssignExpr) IFarmerDetailsDo
+	Joins(fields ...field.RelationField) IFarmerDetailsDo
+	Preload(fields ...field.RelationField) IFarmerDetailsDo
+	FirstOrInit() (*models.FarmerDetails, error)
+	FirstOrCreate() (*models.FarmerDetails, error)
+	FindByPage(offset int, limit int) (result []*models.FarmerDetails, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Rows() (*sql.Rows, error)
+	Row() *sql.Row
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) IFarmerDetailsDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (f farmerDetailsDo) Debug() IFarmerDetailsDo {
+	return f.withDO(f.DO.Debug())
+}
+
+func (f farmerDetailsDo) WithContext(ctx context.Context) IFarmerDetailsDo {
+	return f.withDO(f.DO.WithContext(ctx))
+}
+
+func (f farmerDetailsDo) ReadDB() IFarmerDetailsDo {
+	return f.Clauses(dbresolver.Read)
+}
+
+func (f farmerDetailsDo) WriteDB() IFarmerDetailsDo {
+	return f.Clauses(dbresolver.Write)
+}
+
+func (f farmerDetailsDo) Session(config *gorm.Session) IFarmerDetailsDo {
+	return f.withDO(f.DO.Session(config))
+}
+
+func (f farmerDetailsDo) Clauses(conds ...clause.Expression) IFarmerDetailsDo {
+	return f.withDO(f.DO.Clauses(conds...))
+}
+
+func (f farmerDetailsDo) Returning(value interface{}, columns ...string) IFarmerDetailsDo {
+	return f.withDO(f.DO.Returning(value, columns...))
+}
+
+func (f farmerDetailsDo) Not(conds ...gen.Condition) IFarmerDetailsDo {
+	return f.withDO(f.DO.Not(conds...))
+}
+
+func (f farmerDetailsDo) Or(conds ...gen.Condition) IFarmerDetailsDo {
+	return f.withDO(f.DO.Or(conds...))
+}
+
+func (f farmerDetailsDo) Select(conds ...field.Expr) IFarmerDetailsDo {
+	return f.withDO(f.DO.Select(conds...))
+}
+
+func (f farmerDetailsDo) Where(conds ...gen.Condition) IFarmerDetailsDo {
+	return f.withDO(f.DO.Where(conds...))
+}
+
+func (f farmerDetailsDo) Order(conds ...field.Expr) IFarmerDetailsDo {
+	return f.withDO(f.DO.Order(conds...))
+}
+
+func (f farmerDetailsDo) Distinct(cols ...field.Expr) IFarmerDetailsDo {
+	return f.withDO(f.DO.Distinct(cols...))
+}
+
+func (f farmerDetailsDo) Omit(cols ...field.Expr) IFarmerDetailsDo {
+	return f.withDO(f.DO.Omit(cols...))
+}
+
+func (f farmerDetailsDo) Join(table schema.Tabler, on ...field.Expr) IFarmerDetailsDo {
+	return f.withDO(f.DO.Join(table, on...))
+}
+
+func (f farmerDetailsDo) LeftJoin(table schema.Tabler, on ...field.Expr) IFarmerDetailsDo {
+	return f.withDO(f.DO.LeftJoin(table, on...))
+}
+
+func (f farmerDetailsDo) RightJoin(table schema.Tabler, on ...field.Expr) IFarmerDetailsDo {
+	return f.withDO(f.DO.RightJoin(table, on...))
+}
+
+func (f farmerDetailsDo) Group(cols ...field.Expr) IFarmerDetailsDo {
+	return f.withDO(f.DO.Group(cols...))
+}
+
+func (f farmerDetailsDo) Having(conds ...gen.Condition) IFarmerDetailsDo {
+	return f.withDO(f.DO.Having(conds...))
+}
+
+func (f farmerDetailsDo) Limit(limit int) IFarmerDetailsDo {
+	return f.withDO(f.DO.Limit(limit))
+}
+
+func (f farmerDetailsDo) Offset(offset int) IFarmerDetailsDo {
+	return f.withDO(f.DO.Offset(offset))
+}
+
+func (f farmerDetailsDo) Scopes(funcs ...func(gen.Dao) gen.Dao) IFarmerDetailsDo {
+	return f.withDO(f.DO.Scopes(funcs...))
+}
+
+func (f farmerDetailsDo) Unscoped() IFarmerDetailsDo {
+	return f.withDO(f.DO.Unscoped())
+}
+
+func (f farmerDetailsDo) Create(values ...*models.FarmerDetails) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return f.DO.Create(values)
+}
+
+func (f farmerDetailsDo) CreateInBatches(values []*models.FarmerDetails, batchSize int) error {
+	return f.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (f farmerDetailsDo) Save(values ...*models.FarmerDetails) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return f.DO.Save(values)
+}
+
+func (f farmerDetailsDo) First() (*models.FarmerDetails, error) {
+	if result, err := f.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*models.FarmerDetails), nil
+	}
+}
+
+func (f farmerDetailsDo) Take() (*models.FarmerDetails, error) {
+	if result, err := f.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*models.FarmerDetails), nil
+	}
+}
+
+func (f farmerDetailsDo) Last() (*models.FarmerDetails, error) {
+	if result, err := f.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*models.FarmerDetails), nil
+	}
+}
+
+func (f farmerDetailsDo) Find() ([]*models.FarmerDetails, error) {
+	result, err := f.DO.Find()
+	return result.([]*models.FarmerDetails), err
+}
+
+func (f farmerDetailsDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*models.FarmerDetails, err error) {
+	buf := make([]*models.FarmerDetails, 0, batchSize)
+	err = f.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (f farmerDetailsDo) FindInBatches(result *[]*models.FarmerDetails, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return f.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (f farmerDetailsDo) Attrs(attrs ...field.AssignExpr) IFarmerDetailsDo {
+	return f.withDO(f.DO.Attrs(attrs...))
+}
+
+func (f farmerDetailsDo) Assign(attrs ...field.AssignExpr) IFarmerDetailsDo {
+	return f.withDO(f.DO.Assign(attrs...))
+}
+
+func (f farmerDetailsDo) Joins(fields ...field.RelationField) IFarmerDetailsDo {
+	for _, _f := range fields {
+		f = *f.withDO(f.DO.Joins(_f))
+	}
+	return &f
+}
+
+func (f farmerDetailsDo) Preload(fields ...field.RelationField) IFarmerDetailsDo {
+	for _, _f := range fields {
+		f = *f.withDO(f.DO.Preload(_f))
+	}
+	return &f
+}
+
+func (f farmerDetailsDo) FirstOrInit() (*models.FarmerDetails, error) {
+	if result, err := f.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*models.FarmerDetails), nil
+	}
+}
+
+func (f farmerDetailsDo) FirstOrCreate() (*models.FarmerDetails, error) {
+	if result, err := f.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*models.FarmerDetails), nil
+	}
+}
+
+func (f farmerDetailsDo) FindByPage(offset int, limit int) (result []*models.FarmerDetails, count int64, err error) {
+	result, err = f.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = f.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (f farmerDetailsDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = f.Count()
+	if err != nil {
+		return
+	}
+
+	err = f.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (f farmerDetailsDo) Scan(result interface{}) (err error) {
+	return f.DO.Scan(result)
+}
+
+func (f farmerDetailsDo) Delete(models ...*models.FarmerDetails) (result gen.ResultInfo, err error) {
+	return f.DO.Delete(models)
+}
+
+func (f *farmerDetailsDo) withDO(do gen.Dao) *farmerDetailsDo {
+	f.DO = *do.(*gen.DO)
+	return f
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"gorm.io/gorm"
+)
+
+// ErrClubNotFound is returned when the cooperative has no club with the given ID
+var ErrClubNotFound = errors.New("club not found")
+
+// ClubRepository is the storage of the clubs of every cooperative
+type ClubRepository interface {
+	Find(coopID, clubID string) (*models.Club, error)
+	// Save creates the club or replaces the one with the same coop and club ID
+	Save(club *models.Club) error
+	// List returns every club, ordered by coop and club ID
+	List() ([]models.Club, error)
+	// Truncate removes every club
+	Truncate() error
+}
+
+type gormClubRepository struct {
+	db *gorm.DB
+}
+
+// NewGormClubRepository returns a ClubRepository backed by GORM
+func NewGormClubRepository(db *gorm.DB) ClubRepository {
+	return &gormClubRepository{db: db}
+}
+
+func (r *gormClubRepository) Find(coopID, clubID string) (*models.Club, error) {
+	var club models.Club
+	if err := r.db.Where("coop_id = ? AND id = ?", coopID, clubID).First(&club).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClubNotFound
+		}
+		return nil, err
+	}
+	return &club, nil
+}
+
+func (r *gormClubRepository) Save(club *models.Club) error {
+	return r.db.Save(club).Error
+}
+
+func (r *gormClubRepository) List() ([]models.Club, error) {
+	var clubs []models.Club
+	if err := r.db.Order("coop_id, id").Find(&clubs).Error; err != nil {
+		return nil, err
+	}
+	return clubs, nil
+}
+
+func (r *gormClubRepository) Truncate() error {
+	return r.db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Club{}).Error
+}
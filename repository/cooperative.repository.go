@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"gorm.io/gorm"
+)
+
+// ErrCooperativeNotFound is returned when no cooperative has the given ID
+var ErrCooperativeNotFound = errors.New("cooperative not found")
+
+// CooperativeRepository is the storage used by the cooperative registry
+type CooperativeRepository interface {
+	Find(id string) (*models.Cooperative, error)
+	List() ([]models.Cooperative, error)
+	// Save creates the cooperative or replaces the one with the same ID
+	Save(coop *models.Cooperative) error
+	Delete(id string) error
+	// Truncate removes every cooperative
+	Truncate() error
+}
+
+type gormCooperativeRepository struct {
+	db *gorm.DB
+}
+
+// NewGormCooperativeRepository returns a CooperativeRepository backed by GORM
+func NewGormCooperativeRepository(db *gorm.DB) CooperativeRepository {
+	return &gormCooperativeRepository{db: db}
+}
+
+func (r *gormCooperativeRepository) Find(id string) (*models.Cooperative, error) {
+	var coop models.Cooperative
+	if err := r.db.Where("id = ?", id).First(&coop).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCooperativeNotFound
+		}
+		return nil, err
+	}
+	return &coop, nil
+}
+
+func (r *gormCooperativeRepository) List() ([]models.Cooperative, error) {
+	var coops []models.Cooperative
+	if err := r.db.Order("id").Find(&coops).Error; err != nil {
+		return nil, err
+	}
+	return coops, nil
+}
+
+func (r *gormCooperativeRepository) Save(coop *models.Cooperative) error {
+	return r.db.Save(coop).Error
+}
+
+func (r *gormCooperativeRepository) Delete(id string) error {
+	result := r.db.Delete(&models.Cooperative{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCooperativeNotFound
+	}
+	return nil
+}
+
+func (r *gormCooperativeRepository) Truncate() error {
+	return r.db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Cooperative{}).Error
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/query"
+	"gorm.io/gen/field"
+	"gorm.io/gorm"
+)
+
+// ErrFarmerNotFound is returned when no farmer matches a lookup
+var ErrFarmerNotFound = errors.New("farmer not found")
+
+// Returned by Create, UpdateFields and Restore when the write would break a unique
+// key, e.g. when a concurrent request registered the same farmer first
+var (
+	ErrDuplicateFarmer = errors.New("farmer ID already registered in the cooperative")
+	ErrDuplicateKYC    = errors.New("KYC ID already registered")
+)
+
+// ErrRoleChanged is returned by UpdateRole when the stored farmer no longer
+// has the role in the expected status, or was deleted
+var ErrRoleChanged = errors.New("farmer role changed")
+
+// FarmerFilter narrows down List results. Zero values are ignored.
+type FarmerFilter struct {
+	CoopID      string
+	ClubID      string
+	Role        string     // models.RoleCustomer or models.RoleVendor
+	UpdatedFrom *time.Time // inclusive
+	UpdatedTo   *time.Time // exclusive
+	WithKYC     bool       // only farmers registered with their own KYC ID
+	WithDeleted bool       // include soft-deleted farmers
+
+	// Status and RegisteredBefore apply to the registration of Role
+	Status           string
+	RegisteredBefore *time.Time
+
+	Limit  int // 0 returns every match
+	Offset int
+}
+
+// FarmerRepository is the storage used by the farmer handlers
+type FarmerRepository interface {
+	Create(farmer *models.FarmerDetails) error
+	// FindByKYC never matches an empty KYC ID, so club members registered
+	// without one do not collide with each other
+	FindByKYC(kycID string) (*models.FarmerDetails, error)
+	FindByCoopAndFarmer(coopID, farmerID string) (*models.FarmerDetails, error)
+	List(filter FarmerFilter) ([]models.FarmerDetails, int64, error)
+	// UpdateFields writes only the given FarmerDetails fields, by Go field
+	// name, and updated_at, so columns it does not name keep what concurrent
+	// writes stored. It returns ErrFarmerNotFound when the stored farmer was
+	// deleted.
+	UpdateFields(farmer *models.FarmerDetails, fields []string) error
+	// UpdateRole writes only the columns of role (its ERP and temp IDs,
+	// status and timestamps) and updated_at, provided the stored farmer is not
+	// deleted and the role is still in status from. Other columns keep what
+	// concurrent writes stored. It returns ErrRoleChanged otherwise.
+	UpdateRole(farmer *models.FarmerDetails, role, from string) error
+	// Delete soft-deletes the farmer, hiding it from every lookup until
+	// restored, and sets updated_at in the same write so that incremental
+	// syncs with includeDeleted see the removal
+	Delete(farmer *models.FarmerDetails) error
+	FindDeleted(coopID, farmerID string) (*models.FarmerDetails, error)
+	Restore(farmer *models.FarmerDetails) error
+	// Truncate permanently removes every farmer, soft-deleted ones included
+	Truncate() error
+	// Import stores farmers as they are, keeping IDs and timestamps, the way
+	// a snapshot holds them. Farmers without an ID get a new one.
+	Import(farmers []models.FarmerDetails) error
+}
+
+// gormFarmerRepository reads through the gorm/gen DAO of the query package
+// (go run ./cmd/generate) and writes through db, whose hooks keep the
+// unique keys in step
+type gormFarmerRepository struct {
+	db *gorm.DB
+	q  *query.Query
+}
+
+// NewGormFarmerRepository returns a FarmerRepository backed by GORM
+func NewGormFarmerRepository(db *gorm.DB) FarmerRepository {
+	return &gormFarmerRepository{db: db, q: query.Use(db)}
+}
+
+func (r *gormFarmerRepository) Create(farmer *models.FarmerDetails) error {
+	return translateDuplicate(r.db.Create(farmer).Error)
+}
+
+func (r *gormFarmerRepository) FindByKYC(kycID string) (*models.FarmerDetails, error) {
+	if kycID == "" {
+		return nil, ErrFarmerNotFound
+	}
+	f := r.q.FarmerDetails
+	return first(f.Where(f.FarmerKycID.Eq(kycID)).First())
+}
+
+func (r *gormFarmerRepository) FindByCoopAndFarmer(coopID, farmerID string) (*models.FarmerDetails, error) {
+	f := r.q.FarmerDetails
+	return first(f.Where(f.CoopID.Eq(coopID), f.FarmerID.Eq(farmerID)).First())
+}
+
+func (r *gormFarmerRepository) List(filter FarmerFilter) ([]models.FarmerDetails, int64, error) {
+	f := r.q.FarmerDetails
+	do := f.WithContext(context.Background())
+	if filter.WithDeleted {
+		do = do.Unscoped()
+	}
+	if filter.CoopID != "" {
+		do = do.Where(f.CoopID.Eq(filter.CoopID))
+	}
+	if filter.ClubID != "" {
+		do = do.Where(f.ClubID.Eq(filter.ClubID))
+	}
+	if filter.Role == models.RoleCustomer || filter.Role == models.RoleVendor {
+		status, registeredAt := f.CustomerStatus, f.CustomerRegisteredAt
+		if filter.Role == models.RoleVendor {
+			status, registeredAt = f.VendorStatus, f.VendorRegisteredAt
+		}
+		do = do.Where(status.Neq(""))
+		if filter.Status != "" {
+			do = do.Where(status.Eq(filter.Status))
+		}
+		if filter.RegisteredBefore != nil {
+			// Rows without a registration time fall back to created_at, see
+			// models.FarmerDetails.RoleRegisteredAt
+			do = do.Where(field.Or(
+				registeredAt.Lte(*filter.RegisteredBefore),
+				field.And(registeredAt.IsNull(), f.CreatedAt.Lte(*filter.RegisteredBefore)),
+			))
+		}
+	}
+	if filter.UpdatedFrom != nil {
+		do = do.Where(f.UpdatedAt.Gte(*filter.UpdatedFrom))
+	}
+	if filter.UpdatedTo != nil {
+		do = do.Where(f.UpdatedAt.Lt(*filter.UpdatedTo))
+	}
+	if filter.WithKYC {
+		do = do.Where(f.FarmerKycID.Neq(""))
+	}
+
+	total, err := do.Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Limit > 0 {
+		do = do.Limit(filter.Limit).Offset(filter.Offset)
+	}
+	found, err := do.Order(f.ID).Find()
+	if err != nil {
+		return nil, 0, err
+	}
+	farmers := make([]models.FarmerDetails, len(found))
+	for i, farmer := range found {
+		farmers[i] = *farmer
+	}
+	return farmers, total, nil
+}
+
+func (r *gormFarmerRepository) UpdateFields(farmer *models.FarmerDetails, fields []string) error {
+	names := append([]string{"UpdatedAt"}, fields...)
+	// BeforeSave derives the KYC unique key from the KYC ID being written
+	if slices.Contains(fields, "FarmerKycID") {
+		names = append(names, "KycKey")
+	}
+
+	// The soft delete scope adds deleted_at IS NULL
+	result := r.db.Model(farmer).Select(names).Updates(farmer)
+	if result.Error != nil {
+		return translateDuplicate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrFarmerNotFound
+	}
+	return nil
+}
+
+func (r *gormFarmerRepository) UpdateRole(farmer *models.FarmerDetails, role, from string) error {
+	f := r.q.FarmerDetails
+	status, columns := f.CustomerStatus, []field.Expr{f.CustomerID, f.TempID, f.CustomerStatus, f.CustIDUpdateAt, f.CustomerRegisteredAt, f.UpdatedAt}
+	if role == models.RoleVendor {
+		status, columns = f.VendorStatus, []field.Expr{f.VendorID, f.TempVendorID, f.VendorStatus, f.VendorIDUpdateAt, f.VendorRegisteredAt, f.UpdatedAt}
+	}
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = string(column.ColumnName())
+	}
+
+	// The soft delete scope adds deleted_at IS NULL
+	result := r.db.Model(farmer).Where(status.Eq(from)).Select(names).Updates(farmer)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRoleChanged
+	}
+	return nil
+}
+
+// Delete sets DeletedAt with a plain update instead of GORM's soft delete, so
+// that BeforeSave releases the unique keys in the same statement
+func (r *gormFarmerRepository) Delete(farmer *models.FarmerDetails) error {
+	deleted := *farmer
+	now := time.Now().UTC()
+	deleted.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	deleted.UpdatedAt = &now
+	result := r.db.Model(&deleted).Select("deleted_at", "updated_at", "farmer_key", "kyc_key").Updates(&deleted)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFarmerNotFound
+	}
+	farmer.DeletedAt, farmer.UpdatedAt = deleted.DeletedAt, deleted.UpdatedAt
+	return nil
+}
+
+func (r *gormFarmerRepository) FindDeleted(coopID, farmerID string) (*models.FarmerDetails, error) {
+	f := r.q.FarmerDetails
+	return first(f.Unscoped().Where(f.CoopID.Eq(coopID), f.FarmerID.Eq(farmerID), f.DeletedAt.IsNotNull()).First())
+}
+
+func (r *gormFarmerRepository) Restore(farmer *models.FarmerDetails) error {
+	farmer.DeletedAt = gorm.DeletedAt{}
+	return translateDuplicate(r.db.Unscoped().Save(farmer).Error)
+}
+
+func (r *gormFarmerRepository) Truncate() error {
+	return r.db.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(&models.FarmerDetails{}).Error
+}
+
+// Import inserts row by row: batch inserts write NULL columns as DEFAULT,
+// which SQLite does not accept
+func (r *gormFarmerRepository) Import(farmers []models.FarmerDetails) error {
+	// The hooks would replace the imported timestamps
+	return r.db.Session(&gorm.Session{SkipHooks: true}).Transaction(func(tx *gorm.DB) error {
+		for i := range farmers {
+			farmers[i].SetUniqueKeys()
+			if err := tx.Create(&farmers[i]).Error; err != nil {
+				return translateDuplicate(err)
+			}
+		}
+		return nil
+	})
+}
+
+// translateDuplicate maps a unique index violation reported by MySQL
+// ("Duplicate entry ... for key 'idx_farmer_details_farmer_key'") or SQLite
+// ("UNIQUE constraint failed: farmer_details.farmer_key") to the matching
+// duplicate error
+func translateDuplicate(err error) error {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	if !strings.Contains(message, "Duplicate entry") && !strings.Contains(message, "UNIQUE constraint failed") {
+		return err
+	}
+	switch {
+	case strings.Contains(message, "farmer_key"):
+		return ErrDuplicateFarmer
+	case strings.Contains(message, "kyc_key"):
+		return ErrDuplicateKYC
+	}
+	return err
+}
+
+// first maps the not-found error of a generated First to ErrFarmerNotFound
+func first(farmer *models.FarmerDetails, err error) (*models.FarmerDetails, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFarmerNotFound
+	}
+	return farmer, err
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"maps"
+	"sort"
+	"sync"
+
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+type clubKey struct {
+	coopID string
+	clubID string
+}
+
+// memoryClubs is the data shared by a memoryClubRepository and the views of
+// it that transactions hand out
+type memoryClubs struct {
+	mu    sync.RWMutex
+	clubs map[clubKey]models.Club
+}
+
+type memoryClubRepository struct {
+	*memoryClubs
+	writes sync.Locker // see NewMemoryRepositories
+}
+
+// NewMemoryClubRepository returns a ClubRepository that keeps every club in
+// process memory
+func NewMemoryClubRepository() ClubRepository {
+	return &memoryClubRepository{memoryClubs: &memoryClubs{clubs: map[clubKey]models.Club{}}, writes: &sync.Mutex{}}
+}
+
+func (r *memoryClubRepository) view() *memoryClubRepository {
+	return &memoryClubRepository{memoryClubs: r.memoryClubs, writes: noLock{}}
+}
+
+func (r *memoryClubRepository) Find(coopID, clubID string) (*models.Club, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	club, ok := r.clubs[clubKey{coopID, clubID}]
+	if !ok {
+		return nil, ErrClubNotFound
+	}
+	return &club, nil
+}
+
+func (r *memoryClubRepository) Save(club *models.Club) error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clubs[clubKey{club.CoopID, club.ID}] = *club
+	return nil
+}
+
+func (r *memoryClubRepository) List() ([]models.Club, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clubs := make([]models.Club, 0, len(r.clubs))
+	for _, club := range r.clubs {
+		clubs = append(clubs, club)
+	}
+	sort.Slice(clubs, func(i, j int) bool {
+		if clubs[i].CoopID != clubs[j].CoopID {
+			return clubs[i].CoopID < clubs[j].CoopID
+		}
+		return clubs[i].ID < clubs[j].ID
+	})
+	return clubs, nil
+}
+
+// snapshot copies the clubs and returns the function that puts them back
+func (r *memoryClubRepository) snapshot() (rollback func()) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clubs := maps.Clone(r.clubs)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.clubs = clubs
+	}
+}
+
+func (r *memoryClubRepository) Truncate() error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clubs = map[clubKey]models.Club{}
+	return nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"maps"
+	"sort"
+	"sync"
+
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// memoryCooperatives is the data shared by a memoryCooperativeRepository
+// and the views of it that transactions hand out
+type memoryCooperatives struct {
+	mu    sync.RWMutex
+	coops map[string]models.Cooperative
+}
+
+type memoryCooperativeRepository struct {
+	*memoryCooperatives
+	writes sync.Locker // see NewMemoryRepositories
+}
+
+// NewMemoryCooperativeRepository returns a CooperativeRepository that keeps
+// every cooperative in process memory
+func NewMemoryCooperativeRepository() CooperativeRepository {
+	return &memoryCooperativeRepository{memoryCooperatives: &memoryCooperatives{coops: map[string]models.Cooperative{}}, writes: &sync.Mutex{}}
+}
+
+func (r *memoryCooperativeRepository) view() *memoryCooperativeRepository {
+	return &memoryCooperativeRepository{memoryCooperatives: r.memoryCooperatives, writes: noLock{}}
+}
+
+func (r *memoryCooperativeRepository) Find(id string) (*models.Cooperative, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	coop, ok := r.coops[id]
+	if !ok {
+		return nil, ErrCooperativeNotFound
+	}
+	return &coop, nil
+}
+
+func (r *memoryCooperativeRepository) List() ([]models.Cooperative, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	coops := make([]models.Cooperative, 0, len(r.coops))
+	for _, coop := range r.coops {
+		coops = append(coops, coop)
+	}
+	sort.Slice(coops, func(i, j int) bool { return coops[i].ID < coops[j].ID })
+	return coops, nil
+}
+
+func (r *memoryCooperativeRepository) Save(coop *models.Cooperative) error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.coops[coop.ID] = *coop
+	return nil
+}
+
+func (r *memoryCooperativeRepository) Delete(id string) error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.coops[id]; !ok {
+		return ErrCooperativeNotFound
+	}
+	delete(r.coops, id)
+	return nil
+}
+
+// snapshot copies the cooperatives and returns the function that puts them
+// back
+func (r *memoryCooperativeRepository) snapshot() (rollback func()) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	coops := maps.Clone(r.coops)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.coops = coops
+	}
+}
+
+func (r *memoryCooperativeRepository) Truncate() error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.coops = map[string]models.Cooperative{}
+	return nil
+}
@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"gorm.io/gorm"
+)
+
+// memoryFarmers is the data shared by a memoryFarmerRepository and the views
+// of it that transactions hand out
+type memoryFarmers struct {
+	mu      sync.RWMutex
+	nextID  uint
+	farmers []models.FarmerDetails // ordered by ID
+}
+
+type memoryFarmerRepository struct {
+	*memoryFarmers
+	writes sync.Locker // see NewMemoryRepositories
+}
+
+// NewMemoryFarmerRepository returns a FarmerRepository that keeps every
+// record in process memory. Data is lost when the server stops.
+func NewMemoryFarmerRepository() FarmerRepository {
+	return &memoryFarmerRepository{memoryFarmers: &memoryFarmers{nextID: 1}, writes: &sync.Mutex{}}
+}
+
+// view shares the data but not the write lock, for the transaction that
+// holds it
+func (r *memoryFarmerRepository) view() *memoryFarmerRepository {
+	return &memoryFarmerRepository{memoryFarmers: r.memoryFarmers, writes: noLock{}}
+}
+
+func (r *memoryFarmerRepository) Create(farmer *models.FarmerDetails) error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkUnique(farmer); err != nil {
+		return err
+	}
+	if err := farmer.BeforeCreate(nil); err != nil {
+		return err
+	}
+	farmer.ID = r.nextID
+	r.nextID++
+
+	r.farmers = append(r.farmers, *farmer)
+	return nil
+}
+
+func (r *memoryFarmerRepository) FindByKYC(kycID string) (*models.FarmerDetails, error) {
+	return r.first(func(f *models.FarmerDetails) bool {
+		return !f.DeletedAt.Valid && kycID != "" && f.FarmerKycID == kycID
+	})
+}
+
+func (r *memoryFarmerRepository) FindByCoopAndFarmer(coopID, farmerID string) (*models.FarmerDetails, error) {
+	return r.first(func(f *models.FarmerDetails) bool {
+		return !f.DeletedAt.Valid && f.CoopID == coopID && f.FarmerID == farmerID
+	})
+}
+
+func (r *memoryFarmerRepository) List(filter FarmerFilter) ([]models.FarmerDetails, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []models.FarmerDetails
+	for _, f := range r.farmers {
+		if matchesFilter(&f, &filter) {
+			matches = append(matches, f)
+		}
+	}
+
+	total := int64(len(matches))
+	if filter.Limit > 0 {
+		if filter.Offset >= len(matches) {
+			return []models.FarmerDetails{}, total, nil
+		}
+		end := filter.Offset + filter.Limit
+		if end > len(matches) {
+			end = len(matches)
+		}
+		matches = matches[filter.Offset:end]
+	}
+	return matches, total, nil
+}
+
+// replace overwrites the whole stored farmer
+func (r *memoryFarmerRepository) replace(farmer *models.FarmerDetails) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(farmer.ID)
+	if i < 0 {
+		return ErrFarmerNotFound
+	}
+	if err := r.checkUnique(farmer); err != nil {
+		return err
+	}
+	r.farmers[i] = *farmer
+	return nil
+}
+
+func (r *memoryFarmerRepository) UpdateFields(farmer *models.FarmerDetails, fields []string) error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(farmer.ID)
+	if i < 0 || r.farmers[i].DeletedAt.Valid {
+		return ErrFarmerNotFound
+	}
+	updated := r.farmers[i]
+	from, to := reflect.ValueOf(farmer).Elem(), reflect.ValueOf(&updated).Elem()
+	for _, name := range fields {
+		to.FieldByName(name).Set(from.FieldByName(name))
+	}
+	updated.UpdatedAt = farmer.UpdatedAt
+	if err := r.checkUnique(&updated); err != nil {
+		return err
+	}
+	r.farmers[i] = updated
+	return nil
+}
+
+func (r *memoryFarmerRepository) UpdateRole(farmer *models.FarmerDetails, role, from string) error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(farmer.ID)
+	if i < 0 || r.farmers[i].DeletedAt.Valid || r.farmers[i].RoleStatus(role) != from {
+		return ErrRoleChanged
+	}
+	stored := &r.farmers[i]
+	switch role {
+	case models.RoleCustomer:
+		stored.CustomerID, stored.TempID, stored.CustomerStatus = farmer.CustomerID, farmer.TempID, farmer.CustomerStatus
+		stored.CustIDUpdateAt, stored.CustomerRegisteredAt = farmer.CustIDUpdateAt, farmer.CustomerRegisteredAt
+	case models.RoleVendor:
+		stored.VendorID, stored.TempVendorID, stored.VendorStatus = farmer.VendorID, farmer.TempVendorID, farmer.VendorStatus
+		stored.VendorIDUpdateAt, stored.VendorRegisteredAt = farmer.VendorIDUpdateAt, farmer.VendorRegisteredAt
+	}
+	stored.UpdatedAt = farmer.UpdatedAt
+	return nil
+}
+
+func (r *memoryFarmerRepository) Delete(farmer *models.FarmerDetails) error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(farmer.ID)
+	if i < 0 || r.farmers[i].DeletedAt.Valid {
+		return ErrFarmerNotFound
+	}
+	now := time.Now().UTC()
+	farmer.DeletedAt, farmer.UpdatedAt = gorm.DeletedAt{Time: now, Valid: true}, &now
+	r.farmers[i].DeletedAt, r.farmers[i].UpdatedAt = farmer.DeletedAt, farmer.UpdatedAt
+	return nil
+}
+
+func (r *memoryFarmerRepository) FindDeleted(coopID, farmerID string) (*models.FarmerDetails, error) {
+	return r.first(func(f *models.FarmerDetails) bool {
+		return f.DeletedAt.Valid && f.CoopID == coopID && f.FarmerID == farmerID
+	})
+}
+
+func (r *memoryFarmerRepository) Restore(farmer *models.FarmerDetails) error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+	farmer.DeletedAt = gorm.DeletedAt{}
+	return r.replace(farmer)
+}
+
+// snapshot copies the data and returns the function that puts it back
+func (r *memoryFarmerRepository) snapshot() (rollback func()) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nextID := r.nextID
+	farmers := append([]models.FarmerDetails(nil), r.farmers...)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.nextID, r.farmers = nextID, farmers
+	}
+}
+
+func (r *memoryFarmerRepository) Truncate() error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.farmers = nil
+	return nil
+}
+
+// Import checks every farmer against the stored ones and the rest of the
+// batch before storing any
+func (r *memoryFarmerRepository) Import(farmers []models.FarmerDetails) error {
+	r.writes.Lock()
+	defer r.writes.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, nextID := r.farmers, r.nextID
+	for _, farmer := range farmers {
+		if farmer.ID != 0 && r.indexOf(farmer.ID) >= 0 {
+			r.farmers, r.nextID = stored, nextID
+			return ErrDuplicateFarmer
+		}
+		if err := r.checkUnique(&farmer); err != nil {
+			r.farmers, r.nextID = stored, nextID
+			return err
+		}
+		if farmer.ID == 0 {
+			farmer.ID = r.nextID
+		}
+		if farmer.ID >= r.nextID {
+			r.nextID = farmer.ID + 1
+		}
+		r.farmers = append(r.farmers, farmer)
+	}
+	sort.Slice(r.farmers, func(i, j int) bool { return r.farmers[i].ID < r.farmers[j].ID })
+	return nil
+}
+
+func (r *memoryFarmerRepository) first(match func(*models.FarmerDetails) bool) (*models.FarmerDetails, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := range r.farmers {
+		if match(&r.farmers[i]) {
+			farmer := r.farmers[i]
+			return &farmer, nil
+		}
+	}
+	return nil, ErrFarmerNotFound
+}
+
+// checkUnique mirrors the unique keys of the GORM schema. Callers hold mu.
+func (r *memoryFarmerRepository) checkUnique(farmer *models.FarmerDetails) error {
+	if farmer.DeletedAt.Valid {
+		return nil
+	}
+	for i := range r.farmers {
+		other := &r.farmers[i]
+		if other.ID == farmer.ID || other.DeletedAt.Valid {
+			continue
+		}
+		if other.CoopID == farmer.CoopID && other.FarmerID == farmer.FarmerID {
+			return ErrDuplicateFarmer
+		}
+		if farmer.FarmerKycID != "" && other.FarmerKycID == farmer.FarmerKycID {
+			return ErrDuplicateKYC
+		}
+	}
+	return nil
+}
+
+func (r *memoryFarmerRepository) indexOf(id uint) int {
+	for i := range r.farmers {
+		if r.farmers[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchesFilter mirrors the WHERE clauses built by the GORM repository
+func matchesFilter(f *models.FarmerDetails, filter *FarmerFilter) bool {
+	if f.DeletedAt.Valid && !filter.WithDeleted {
+		return false
+	}
+	if filter.CoopID != "" && f.CoopID != filter.CoopID {
+		return false
+	}
+	if filter.ClubID != "" && f.ClubID != filter.ClubID {
+		return false
+	}
+	if filter.Role != "" {
+		if !f.HasRole(filter.Role) {
+			return false
+		}
+		if filter.Status != "" && f.RoleStatus(filter.Role) != filter.Status {
+			return false
+		}
+		registeredAt := f.RoleRegisteredAt(filter.Role)
+		if filter.RegisteredBefore != nil && (registeredAt == nil || registeredAt.After(*filter.RegisteredBefore)) {
+			return false
+		}
+	}
+	if filter.UpdatedFrom != nil && (f.UpdatedAt == nil || f.UpdatedAt.Before(*filter.UpdatedFrom)) {
+		return false
+	}
+	if filter.UpdatedTo != nil && (f.UpdatedAt == nil || !f.UpdatedAt.Before(*filter.UpdatedTo)) {
+		return false
+	}
+	if filter.WithKYC && f.FarmerKycID == "" {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Repositories groups the storage of every entity served by the mock
+type Repositories struct {
+	Farmers      FarmerRepository
+	Cooperatives CooperativeRepository
+	Clubs        ClubRepository
+
+	transaction func(fn func(tx *Repositories) error) error
+}
+
+// Transaction runs fn atomically: every farmer, club and cooperative written
+// through tx is rolled back when fn returns an error
+func (r *Repositories) Transaction(fn func(tx *Repositories) error) error {
+	return r.transaction(fn)
+}
+
+// NewGormRepositories returns repositories backed by the given database
+func NewGormRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Farmers:      NewGormFarmerRepository(db),
+		Cooperatives: NewGormCooperativeRepository(db),
+		Clubs:        NewGormClubRepository(db),
+		transaction: func(fn func(tx *Repositories) error) error {
+			return db.Transaction(func(tx *gorm.DB) error {
+				return fn(NewGormRepositories(tx))
+			})
+		},
+	}
+}
+
+// NewMemoryRepositories returns repositories that keep everything in process
+// memory. Data is lost when the server stops.
+//
+// A transaction rolls back by putting a snapshot of the data back, so no
+// other write may run meanwhile: every write takes the same lock, which a
+// transaction holds until it ends, and fn writes through views that skip it.
+func NewMemoryRepositories() *Repositories {
+	writes := &sync.Mutex{}
+	farmers := NewMemoryFarmerRepository().(*memoryFarmerRepository)
+	clubs := NewMemoryClubRepository().(*memoryClubRepository)
+	cooperatives := NewMemoryCooperativeRepository().(*memoryCooperativeRepository)
+	farmers.writes, clubs.writes, cooperatives.writes = writes, writes, writes
+
+	repos := &Repositories{
+		Farmers:      farmers,
+		Cooperatives: cooperatives,
+		Clubs:        clubs,
+	}
+	repos.transaction = func(fn func(tx *Repositories) error) error {
+		writes.Lock()
+		defer writes.Unlock()
+
+		rollbacks := []func(){farmers.snapshot(), clubs.snapshot(), cooperatives.snapshot()}
+		tx := &Repositories{
+			Farmers:      farmers.view(),
+			Cooperatives: cooperatives.view(),
+			Clubs:        clubs.view(),
+		}
+		// A nested transaction joins this one
+		tx.transaction = func(fn func(tx *Repositories) error) error { return fn(tx) }
+
+		if err := fn(tx); err != nil {
+			for _, rollback := range rollbacks {
+				rollback()
+			}
+			return err
+		}
+		return nil
+	}
+	return repos
+}
+
+// noLock is the write lock of the views handed to a transaction, which
+// already holds the real one
+type noLock struct{}
+
+func (noLock) Lock()   {}
+func (noLock) Unlock() {}
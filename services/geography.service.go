@@ -0,0 +1,246 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// Geography is the read-only geography master data of the ERP. A nil
+// Geography accepts any address.
+type Geography struct {
+	nodes    map[string]map[string]models.GeographyNode // level → ID → node
+	children map[string][]models.GeographyNode          // level + "/" + parent ID → children
+}
+
+// LoadGeography reads the master data from a .csv file with the header
+// type,id,parent_id,name,zip_code or from a .json array of GeographyNode
+func LoadGeography(path string) (*Geography, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var nodes []models.GeographyNode
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		nodes, err = readGeographyCSV(file)
+	} else {
+		err = json.NewDecoder(file).Decode(&nodes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewGeography(nodes)
+}
+
+// NewGeography indexes the nodes, checking that every parent exists
+func NewGeography(nodes []models.GeographyNode) (*Geography, error) {
+	g := &Geography{
+		nodes:    map[string]map[string]models.GeographyNode{},
+		children: map[string][]models.GeographyNode{},
+	}
+	for _, node := range nodes {
+		if _, ok := models.GeographyParentLevel[node.Type]; !ok {
+			return nil, fmt.Errorf("unknown geography level %q for %q", node.Type, node.ID)
+		}
+		if node.ID == "" {
+			return nil, fmt.Errorf("a %s has no id", node.Type)
+		}
+		if g.nodes[node.Type] == nil {
+			g.nodes[node.Type] = map[string]models.GeographyNode{}
+		}
+		if _, ok := g.nodes[node.Type][node.ID]; ok {
+			return nil, fmt.Errorf("duplicate %s %q", node.Type, node.ID)
+		}
+		g.nodes[node.Type][node.ID] = node
+	}
+
+	for _, node := range nodes {
+		parentLevel := models.GeographyParentLevel[node.Type]
+		if parentLevel == "" {
+			continue
+		}
+		if _, ok := g.nodes[parentLevel][node.ParentID]; !ok {
+			return nil, fmt.Errorf("%s %q: parent %s %q does not exist", node.Type, node.ID, parentLevel, node.ParentID)
+		}
+		key := parentLevel + "/" + node.ParentID
+		g.children[key] = append(g.children[key], node)
+	}
+	return g, nil
+}
+
+func readGeographyCSV(r io.Reader) ([]models.GeographyNode, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	column := map[string]int{}
+	for i, name := range rows[0] {
+		column[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"type", "id", "parent_id", "name", "zip_code"} {
+		if _, ok := column[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	nodes := make([]models.GeographyNode, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		nodes = append(nodes, models.GeographyNode{
+			Type:     strings.TrimSpace(row[column["type"]]),
+			ID:       strings.TrimSpace(row[column["id"]]),
+			ParentID: strings.TrimSpace(row[column["parent_id"]]),
+			Name:     strings.TrimSpace(row[column["name"]]),
+			ZipCode:  strings.TrimSpace(row[column["zip_code"]]),
+		})
+	}
+	return nodes, nil
+}
+
+// Find returns the node of the given level and ID
+func (g *Geography) Find(level string, id string) (models.GeographyNode, bool) {
+	if g == nil {
+		return models.GeographyNode{}, false
+	}
+	node, ok := g.nodes[level][id]
+	return node, ok
+}
+
+// List returns the nodes of a level ordered by ID, narrowed to the children
+// of parentID and to zipCode when those are set
+func (g *Geography) List(level string, parentID string, zipCode string) []models.GeographyNode {
+	if g == nil {
+		return []models.GeographyNode{}
+	}
+
+	nodes := []models.GeographyNode{}
+	for _, node := range g.nodes[level] {
+		if (parentID == "" || node.ParentID == parentID) && (zipCode == "" || node.ZipCode == zipCode) {
+			nodes = append(nodes, node)
+		}
+	}
+	sortNodes(nodes)
+	return nodes
+}
+
+// Children returns the direct children of a node ordered by ID
+func (g *Geography) Children(level string, id string) []models.GeographyNode {
+	if g == nil {
+		return []models.GeographyNode{}
+	}
+	nodes := append([]models.GeographyNode{}, g.children[level+"/"+id]...)
+	sortNodes(nodes)
+	return nodes
+}
+
+// Address is the geography part of a farmer payload. Zero IDs and "0" or
+// empty custom IDs mean not given.
+type Address struct {
+	RegionID         int
+	RegionPartID     int
+	SettlementID     int
+	SettlementPartID int
+	CustomGeo1ID     string
+	CustomGeo2ID     string
+	ZipCode          string
+}
+
+// Validate checks that every given ID exists, that each belongs to the one
+// given above it, and that the zip code matches the most precise node that
+// has one. Errors name the fields the way the create payload does.
+func (g *Geography) Validate(a Address) []*models.ErrorResponse {
+	if g == nil {
+		return nil
+	}
+
+	var errs []*models.ErrorResponse
+	fail := func(field string, message string) {
+		errs = append(errs, &models.ErrorResponse{Field: field, Tag: "geography", Message: message})
+	}
+
+	type level struct {
+		field string
+		level string
+		id    string
+	}
+	chains := [][]level{
+		{
+			{"regionId", models.GeoRegion, geoID(a.RegionID)},
+			{"regionPartID", models.GeoRegionPart, geoID(a.RegionPartID)},
+			{"settlementID", models.GeoSettlement, geoID(a.SettlementID)},
+			{"settlementPartID", models.GeoSettlementPart, geoID(a.SettlementPartID)},
+		},
+		{
+			{"custom_geography_structure1_id", models.GeoCustom1, customGeoID(a.CustomGeo1ID)},
+			{"custom_geography_structure2_id", models.GeoCustom2, customGeoID(a.CustomGeo2ID)},
+		},
+	}
+
+	var zipNode *models.GeographyNode
+	for _, chain := range chains {
+		for i, l := range chain {
+			if l.id == "" {
+				continue
+			}
+			node, ok := g.Find(l.level, l.id)
+			if !ok {
+				fail(l.field, fmt.Sprintf("%s %s does not exist", l.field, l.id))
+				continue
+			}
+			if i > 0 {
+				parent := chain[i-1]
+				if parent.id == "" {
+					fail(l.field, fmt.Sprintf("%s requires %s", l.field, parent.field))
+				} else if node.ParentID != parent.id {
+					fail(l.field, fmt.Sprintf("%s %s does not belong to %s %s", l.field, l.id, parent.field, parent.id))
+				}
+			}
+			if node.ZipCode != "" && chain[0].level == models.GeoRegion {
+				zipNode = &node
+			}
+		}
+	}
+
+	if a.ZipCode != "" && zipNode != nil && zipNode.ZipCode != a.ZipCode {
+		fail("ZipCode", fmt.Sprintf("ZipCode %s does not match %s %s (%s)", a.ZipCode, zipNode.Type, zipNode.ID, zipNode.ZipCode))
+	}
+	return errs
+}
+
+func geoID(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+func customGeoID(id string) string {
+	if id == "0" {
+		return ""
+	}
+	return id
+}
+
+func sortNodes(nodes []models.GeographyNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		a, errA := strconv.Atoi(nodes[i].ID)
+		b, errB := strconv.Atoi(nodes[j].ID)
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+}
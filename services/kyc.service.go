@@ -0,0 +1,116 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/shyamsundaar/karino-mock-server/models"
+)
+
+// DefaultKycTypes is the catalogue used when no KYC types file is configured
+var DefaultKycTypes = []models.KycType{
+	{ID: 1, Name: "AADHAAR", Pattern: `^[2-9][0-9]{11}$`},
+	{ID: 2, Name: "PAN", Pattern: `^[A-Z]{5}[0-9]{4}[A-Z]$`},
+	{ID: 3, Name: "VOTER_ID", Pattern: `^[A-Z]{3}[0-9]{7}$`},
+	{ID: 4, Name: "RATION_CARD", Pattern: `^[A-Z0-9]{8,15}$`},
+	{ID: 5, Name: "PASSPORT", Pattern: `^[A-Z][0-9]{7}$`},
+}
+
+// KycCatalogue holds the KYC types the ERP accepts and checks KYC IDs
+// against their format
+type KycCatalogue struct {
+	byID     map[int]models.KycType
+	byName   map[string]models.KycType
+	patterns map[int]*regexp.Regexp
+}
+
+// LoadKycCatalogue reads a JSON array of KycType from path
+func LoadKycCatalogue(path string) (*KycCatalogue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var types []models.KycType
+	if err := json.Unmarshal(data, &types); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewKycCatalogue(types)
+}
+
+// NewKycCatalogue indexes the types, compiling their patterns
+func NewKycCatalogue(types []models.KycType) (*KycCatalogue, error) {
+	k := &KycCatalogue{
+		byID:     map[int]models.KycType{},
+		byName:   map[string]models.KycType{},
+		patterns: map[int]*regexp.Regexp{},
+	}
+	for _, kycType := range types {
+		if _, ok := k.byID[kycType.ID]; ok {
+			return nil, fmt.Errorf("duplicate KYC type ID %d", kycType.ID)
+		}
+		if _, ok := k.byName[kycType.Name]; ok {
+			return nil, fmt.Errorf("duplicate KYC type %q", kycType.Name)
+		}
+		pattern, err := regexp.Compile(kycType.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("KYC type %q: %w", kycType.Name, err)
+		}
+		k.byID[kycType.ID] = kycType
+		k.byName[kycType.Name] = kycType
+		k.patterns[kycType.ID] = pattern
+	}
+	return k, nil
+}
+
+// List returns the catalogue ordered by ID
+func (k *KycCatalogue) List() []models.KycType {
+	types := make([]models.KycType, 0, len(k.byID))
+	for _, kycType := range k.byID {
+		types = append(types, kycType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].ID < types[j].ID })
+	return types
+}
+
+// Validate checks that farmer_kyc_type_id and farmer_kyc_type name a known
+// type, agree with each other when both are given, and that the KYC ID
+// matches the format of the selected type, which a KYC ID requires. A zero
+// typeID and an empty typeName mean not given.
+func (k *KycCatalogue) Validate(typeID int, typeName string, kycID string) []*models.ErrorResponse {
+	var errs []*models.ErrorResponse
+	fail := func(field string, message string) {
+		errs = append(errs, &models.ErrorResponse{Field: field, Tag: "kyc", Message: message})
+	}
+
+	byID, idOK := k.byID[typeID]
+	if typeID != 0 && !idOK {
+		fail("farmer_kyc_type_id", "farmer_kyc_type_id "+strconv.Itoa(typeID)+" is not a known KYC type")
+	}
+	byName, nameOK := k.byName[typeName]
+	if typeName != "" && !nameOK {
+		fail("farmer_kyc_type", "farmer_kyc_type "+typeName+" is not a known KYC type")
+	}
+	if idOK && nameOK && byID.ID != byName.ID {
+		fail("farmer_kyc_type", fmt.Sprintf("farmer_kyc_type %s does not match farmer_kyc_type_id %d (%s)", typeName, typeID, byID.Name))
+	}
+	if len(errs) > 0 || kycID == "" {
+		return errs
+	}
+
+	selected, ok := byID, idOK
+	if !ok {
+		selected, ok = byName, nameOK
+	}
+	if !ok {
+		// Without a type the KYC ID cannot be checked
+		errs = append(errs, &models.ErrorResponse{Field: "farmer_kyc_type_id", Tag: "required", Message: "farmer_kyc_type_id is required when farmer_kyc_id is given"})
+	} else if !k.patterns[selected.ID].MatchString(kycID) {
+		fail("farmer_kyc_id", "farmer_kyc_id is not a valid "+selected.Name+" number")
+	}
+	return errs
+}
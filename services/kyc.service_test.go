@@ -0,0 +1,42 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKycValidate(t *testing.T) {
+	catalogue, err := NewKycCatalogue(DefaultKycTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		typeID   int
+		typeName string
+		kycID    string
+		want     []string // fields reported, in order
+	}{
+		{"by ID", 2, "", "ABCDE1234F", nil},
+		{"by name", 0, "PAN", "ABCDE1234F", nil},
+		{"no KYC at all", 0, "", "", nil},
+		{"type without ID", 2, "PAN", "", nil},
+		{"ID without type", 0, "", "ABCDE1234F", []string{"farmer_kyc_type_id"}},
+		{"unknown type", 9, "", "ABCDE1234F", []string{"farmer_kyc_type_id"}},
+		{"mismatched types", 1, "PAN", "ABCDE1234F", []string{"farmer_kyc_type"}},
+		{"wrong format", 2, "", "1234", []string{"farmer_kyc_id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := catalogue.Validate(tt.typeID, tt.typeName, tt.kycID)
+			var got []string
+			for _, err := range errs {
+				got = append(got, err.Field)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shyamsundaar/karino-mock-server/initializers"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/repository"
+)
+
+// Approval rules understood by the promotion engine
+const (
+	ApprovalRuleAny = "any" // approve every pending registration once the delay has passed
+	ApprovalRuleKyc = "kyc" // only approve farmers registered with their own KYC ID
+)
+
+// PromotionEngine simulates the ERP approving pending customer and vendor
+// registrations: records move from their temp ID to a permanent ERP code.
+type PromotionEngine struct {
+	repo           repository.FarmerRepository
+	delay          time.Duration
+	interval       time.Duration
+	rule           string
+	customerPrefix string
+	vendorPrefix   string
+}
+
+func NewPromotionEngine(repo repository.FarmerRepository, config *initializers.Config) *PromotionEngine {
+	interval := config.ErpApprovalInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	return &PromotionEngine{
+		repo:           repo,
+		delay:          config.ErpApprovalDelay,
+		interval:       interval,
+		rule:           config.ErpApprovalRule,
+		customerPrefix: config.ErpCustomerCodePrefix,
+		vendorPrefix:   config.ErpVendorCodePrefix,
+	}
+}
+
+// Start runs the engine in the background until ctx is cancelled
+func (e *PromotionEngine) Start(ctx context.Context) {
+	log.Printf("Starting ERP approval engine (delay %s, rule %q)", e.delay, e.rule)
+
+	go func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.PromotePending(); err != nil {
+					log.Println("ERP approval run failed:", err.Error())
+				}
+			}
+		}
+	}()
+}
+
+// PromotePending approves every pending registration that is due
+func (e *PromotionEngine) PromotePending() error {
+	cutoff := time.Now().UTC().Add(-e.delay)
+
+	customers := repository.FarmerFilter{Role: models.RoleCustomer}
+	vendors := repository.FarmerFilter{Role: models.RoleVendor}
+	for _, filter := range []*repository.FarmerFilter{&customers, &vendors} {
+		filter.Status = models.RegistrationPending
+		filter.RegisteredBefore = &cutoff
+		filter.WithKYC = e.rule == ApprovalRuleKyc
+	}
+
+	if err := e.promote(customers, e.approveCustomer); err != nil {
+		return err
+	}
+	return e.promote(vendors, e.approveVendor)
+}
+
+func (e *PromotionEngine) promote(filter repository.FarmerFilter, approve func(*models.FarmerDetails, time.Time)) error {
+	pending, _, err := e.repo.List(filter)
+	if err != nil {
+		return err
+	}
+
+	for i := range pending {
+		approve(&pending[i], time.Now().UTC())
+		// Only the role's columns are written, so edits made since the List
+		// are kept; farmers deleted or approved in the meantime are skipped
+		err := e.repo.UpdateRole(&pending[i], filter.Role, models.RegistrationPending)
+		if err != nil && !errors.Is(err, repository.ErrRoleChanged) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *PromotionEngine) approveCustomer(farmer *models.FarmerDetails, now time.Time) {
+	farmer.CustomerID = erpCode(e.customerPrefix, farmer.ID)
+	farmer.CustomerStatus = models.RegistrationApproved
+	farmer.CustIDUpdateAt = &now
+	farmer.UpdatedAt = &now
+}
+
+func (e *PromotionEngine) approveVendor(farmer *models.FarmerDetails, now time.Time) {
+	farmer.VendorID = erpCode(e.vendorPrefix, farmer.ID)
+	farmer.VendorStatus = models.RegistrationApproved
+	farmer.VendorIDUpdateAt = &now
+	farmer.UpdatedAt = &now
+}
+
+// erpCode builds a permanent ERP code such as CUS0000042
+func erpCode(prefix string, id uint) string {
+	return fmt.Sprintf("%s%07d", prefix, id)
+}
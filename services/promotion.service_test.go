@@ -0,0 +1,89 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shyamsundaar/karino-mock-server/initializers"
+	"github.com/shyamsundaar/karino-mock-server/models"
+	"github.com/shyamsundaar/karino-mock-server/repository"
+)
+
+func TestPromotePending(t *testing.T) {
+	now := time.Now().UTC()
+	approvedAt := now.Add(-48 * time.Hour)
+
+	tests := []struct {
+		name         string
+		role         string
+		registeredAt time.Time
+		rule         string
+		kycID        string
+		approved     bool // stored as approved already
+		wantStatus   string
+		wantCode     string // a verb stands for the farmer's ID
+	}{
+		{"due customer", models.RoleCustomer, now.Add(-2 * time.Hour), ApprovalRuleAny, "", false, models.RegistrationApproved, "CUS%07d"},
+		{"due vendor", models.RoleVendor, now.Add(-2 * time.Hour), ApprovalRuleAny, "", false, models.RegistrationApproved, "VEN%07d"},
+		{"within the delay", models.RoleCustomer, now.Add(-30 * time.Minute), ApprovalRuleAny, "", false, models.RegistrationPending, ""},
+		{"kyc rule without KYC", models.RoleCustomer, now.Add(-2 * time.Hour), ApprovalRuleKyc, "", false, models.RegistrationPending, ""},
+		{"kyc rule with KYC", models.RoleVendor, now.Add(-2 * time.Hour), ApprovalRuleKyc, "ABCDE1234F", false, models.RegistrationApproved, "VEN%07d"},
+		{"already approved", models.RoleCustomer, now.Add(-72 * time.Hour), ApprovalRuleAny, "", true, models.RegistrationApproved, "CUS9999999"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewMemoryFarmerRepository()
+			farmer := &models.FarmerDetails{CoopID: "COOP001", FarmerID: "F1", FarmerKycID: tt.kycID}
+			farmer.AddRole(tt.role, tt.registeredAt)
+			if tt.approved {
+				farmer.CustomerID = "CUS9999999"
+				farmer.CustomerStatus = models.RegistrationApproved
+				farmer.CustIDUpdateAt = &approvedAt
+			}
+			if err := repo.Create(farmer); err != nil {
+				t.Fatal(err)
+			}
+
+			engine := NewPromotionEngine(repo, &initializers.Config{
+				ErpApprovalDelay:      time.Hour,
+				ErpApprovalRule:       tt.rule,
+				ErpCustomerCodePrefix: "CUS",
+				ErpVendorCodePrefix:   "VEN",
+			})
+			if err := engine.PromotePending(); err != nil {
+				t.Fatal(err)
+			}
+
+			stored, err := repo.FindByCoopAndFarmer("COOP001", "F1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, updatedAt := stored.CustomerID, stored.CustIDUpdateAt
+			if tt.role == models.RoleVendor {
+				code, updatedAt = stored.VendorID, stored.VendorIDUpdateAt
+			}
+			wantCode := tt.wantCode
+			if strings.Contains(wantCode, "%") {
+				wantCode = fmt.Sprintf(tt.wantCode, stored.ID)
+			}
+
+			if status := stored.RoleStatus(tt.role); status != tt.wantStatus || code != wantCode {
+				t.Errorf("got %s %q, want %s %q", status, code, tt.wantStatus, wantCode)
+			}
+			switch {
+			case tt.approved:
+				if updatedAt == nil || !updatedAt.Equal(approvedAt) {
+					t.Errorf("approval time changed to %v", updatedAt)
+				}
+			case tt.wantStatus == models.RegistrationApproved:
+				if updatedAt == nil || updatedAt.Before(now) {
+					t.Errorf("approval time %v, want a time after %v", updatedAt, now)
+				}
+			case updatedAt != nil:
+				t.Errorf("pending farmer has approval time %v", updatedAt)
+			}
+		})
+	}
+}